@@ -20,11 +20,14 @@ import (
 	"discover/internal/scheduler"
 	"discover/internal/server"
 	"discover/internal/store"
+	"discover/internal/urlcanon"
 )
 
 func main() {
 	var configPath string
+	var migrateURLs bool
 	flag.StringVar(&configPath, "config", "config.json", "path to config file")
+	flag.BoolVar(&migrateURLs, "migrate-urls", false, "recompute canonical article URLs, merge duplicates and exit")
 	flag.Parse()
 
 	cfg, created, err := config.LoadOrInit(configPath)
@@ -46,6 +49,30 @@ func main() {
 	}
 	defer database.Close()
 	st := store.New(database)
+	if n, err := st.SeedSearxInstances(context.Background(), cfg.SearxngInstances); err != nil {
+		log.Fatalf("seed searxng instances: %v", err)
+	} else if n > 0 {
+		log.Printf("seeded %d searxng instance(s) from %s; manage them in the admin UI from now on", n, configPath)
+	}
+	urlRules, err := st.ListURLRules(context.Background())
+	if err != nil {
+		log.Fatalf("migrate urls: %v", err)
+	}
+	if migrateURLs {
+		stats, err := st.RecanonicalizeArticles(context.Background(), urlcanon.New(urlRules).Key)
+		if err != nil {
+			log.Fatalf("migrate urls: %v", err)
+		}
+		log.Printf("migrate urls: scanned=%d rehashed=%d merged=%d skipped=%d", stats.Scanned, stats.Rehashed, stats.Merged, stats.Skipped)
+		return
+	}
+	// Stored hashes from an older canonical form would stop matching new
+	// hits, so upgrade them before anything ingests.
+	if stats, ran, err := st.UpgradeURLCanonicalization(context.Background(), urlcanon.Version, urlcanon.New(urlRules).Key); err != nil {
+		log.Fatalf("migrate urls: %v", err)
+	} else if ran {
+		log.Printf("migrate urls: canonical form v%d: scanned=%d rehashed=%d merged=%d skipped=%d", urlcanon.Version, stats.Scanned, stats.Rehashed, stats.Merged, stats.Skipped)
+	}
 
 	guard, err := auth.New(cfg.AdminSecret, cfg.AdminBindCIDRs)
 	if err != nil {
@@ -56,9 +83,14 @@ func main() {
 		log.Fatalf("init user auth: %v", err)
 	}
 	ingester := ingest.New(cfg, st)
-	sched := scheduler.New(cfg.DailyIngestTime, cfg.IngestIntervalMinutes, ingester)
+	plan, err := cfg.SchedulePlan()
+	if err != nil {
+		log.Fatalf("init scheduler: %v", err)
+	}
+	sched := scheduler.New(plan, ingester)
+	ingester.OnProgress(sched.Progress)
 
-	api := server.New(cfg, st, sched, ingester, guard, userGuard, server.AssetsHandler())
+	api := server.New(cfg, st, sched, ingester, ingester, guard, userGuard, server.AssetsHandler())
 	httpServer := &http.Server{
 		Addr:         cfg.ListenAddress,
 		Handler:      api.Routes(),
@@ -70,6 +102,7 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 	sched.Start(ctx)
+	ingester.StartEnrichment(ctx)
 
 	go func() {
 		<-ctx.Done()
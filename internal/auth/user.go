@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"crypto/md5"
 	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"sync"
@@ -66,6 +68,28 @@ func (g *UserGuard) ValidateCredentials(username, secret, remoteAddr string) err
 	return ErrUserUnauthorized
 }
 
+// ValidateFeverKey checks a Fever API key, which the protocol defines as
+// md5("username:secret"). Failures count towards the same per-IP lockout as
+// interactive sign-in.
+func (g *UserGuard) ValidateFeverKey(apiKey, remoteAddr string) error {
+	ip := remoteIP(remoteAddr)
+	if ip == "" {
+		return ErrUserUnauthorized
+	}
+	if g.isBlocked(ip) {
+		return ErrUserBlocked
+	}
+	sum := md5.Sum([]byte(g.username + ":" + string(g.secret)))
+	expected := hex.EncodeToString(sum[:])
+	provided := strings.ToLower(strings.TrimSpace(apiKey))
+	if len(provided) == len(expected) && subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1 {
+		g.clearAttempts(ip)
+		return nil
+	}
+	g.recordFailure(ip)
+	return ErrUserUnauthorized
+}
+
 func (g *UserGuard) NewSession(remoteAddr string, ttl time.Duration) (string, time.Time, error) {
 	if ttl <= 0 {
 		ttl = 30 * 24 * time.Hour
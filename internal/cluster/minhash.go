@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MinHash signatures over the word set of an article's title and the start of
+// its snippet. The fraction of equal slots in two signatures estimates the
+// Jaccard similarity of the word sets.
+
+const (
+	SignatureSize   = 64
+	snippetMaxWords = 30
+)
+
+var stopwords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be but by for from has have how in into is it its
+		of on or that the their this to was were what when where which who why will with you your
+		after about over new says said just more than all can not`) {
+		stopwords[w] = struct{}{}
+	}
+}
+
+// Signature returns nil when the text has no usable words.
+func Signature(title, content string) []uint32 {
+	words := Words(title)
+	snippet := Words(content)
+	if len(snippet) > snippetMaxWords {
+		snippet = snippet[:snippetMaxWords]
+	}
+	words = append(words, snippet...)
+	if len(words) == 0 {
+		return nil
+	}
+	sig := make([]uint32, SignatureSize)
+	for i := range sig {
+		sig[i] = ^uint32(0)
+	}
+	seen := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(w))
+		base := h.Sum64()
+		for i := range sig {
+			v := uint32(mix64(base ^ (uint64(i+1) * 0x9e3779b97f4a7c15)))
+			if v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity estimates Jaccard similarity; mismatched or empty signatures
+// score 0.
+func Similarity(a, b []uint32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// Words lowercases text and returns its words without stopwords and
+// single-letter tokens.
+func Words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) < 2 {
+			continue
+		}
+		if _, ok := stopwords[f]; ok {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func Encode(sig []uint32) []byte {
+	if len(sig) == 0 {
+		return nil
+	}
+	b := make([]byte, 4*len(sig))
+	for i, v := range sig {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+func Decode(b []byte) []uint32 {
+	if len(b) == 0 || len(b)%4 != 0 {
+		return nil
+	}
+	sig := make([]uint32, len(b)/4)
+	for i := range sig {
+		sig[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return sig
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Index finds candidate neighbours by LSH banding: signatures are cut into
+// bands of rows slots, and two signatures are candidates when any band is
+// identical. Candidates still need a Similarity check.
+type Index struct {
+	rows    int
+	buckets map[uint64][]int
+	sigs    [][]uint32
+}
+
+// NewIndex picks the widest bands that still find pairs at the threshold
+// similarity with high probability; narrower bands mean more candidates.
+func NewIndex(threshold float64) *Index {
+	rows := 1
+	for _, r := range []int{8, 4, 2} {
+		bands := SignatureSize / r
+		if 1-math.Pow(1-math.Pow(threshold, float64(r)), float64(bands)) >= 0.99 {
+			rows = r
+			break
+		}
+	}
+	return &Index{rows: rows, buckets: make(map[uint64][]int)}
+}
+
+// Add stores sig and returns its position, which Candidates reports.
+func (x *Index) Add(sig []uint32) int {
+	pos := len(x.sigs)
+	x.sigs = append(x.sigs, sig)
+	for _, k := range x.keys(sig) {
+		x.buckets[k] = append(x.buckets[k], pos)
+	}
+	return pos
+}
+
+// Candidates lists the positions of stored signatures sharing a band with sig.
+func (x *Index) Candidates(sig []uint32) []int {
+	var out []int
+	seen := make(map[int]struct{})
+	for _, k := range x.keys(sig) {
+		for _, pos := range x.buckets[k] {
+			if _, ok := seen[pos]; ok {
+				continue
+			}
+			seen[pos] = struct{}{}
+			out = append(out, pos)
+		}
+	}
+	return out
+}
+
+// Signature returns the signature stored at pos.
+func (x *Index) Signature(pos int) []uint32 {
+	return x.sigs[pos]
+}
+
+func (x *Index) keys(sig []uint32) []uint64 {
+	if len(sig) != SignatureSize {
+		return nil
+	}
+	keys := make([]uint64, 0, SignatureSize/x.rows)
+	var buf [4]byte
+	for band := 0; band < SignatureSize/x.rows; band++ {
+		h := fnv.New64a()
+		buf[0] = byte(band)
+		_, _ = h.Write(buf[:1])
+		for _, v := range sig[band*x.rows : (band+1)*x.rows] {
+			binary.LittleEndian.PutUint32(buf[:], v)
+			_, _ = h.Write(buf[:])
+		}
+		keys = append(keys, h.Sum64())
+	}
+	return keys
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+
+	"discover/internal/scheduler"
 )
 
 const defaultAdminSecret = "CHANGEME_STRONG_SECRET"
@@ -25,6 +27,9 @@ type Config struct {
 	DatabasePath           string   `json:"database_path"`
 	DailyIngestTime        string   `json:"daily_ingest_time"`
 	IngestIntervalMinutes  int      `json:"ingest_interval_minutes"`
+	IngestCron             string   `json:"ingest_cron"`
+	IngestTimes            []string `json:"ingest_times"`
+	IngestTimezone         string   `json:"ingest_timezone"`
 	SearxngInstances       []string `json:"searxng_instances"`
 	PerQueryDelaySeconds   int      `json:"per_query_delay_seconds"`
 	PerQueryJitterSeconds  int      `json:"per_query_jitter_seconds"`
@@ -37,8 +42,22 @@ type Config struct {
 	AutoHideBelowScore     float64  `json:"auto_hide_below_score"`
 	DedupeTitleKeyChars    int      `json:"dedupe_title_key_chars"`
 	HideRuleDefaultPenalty float64  `json:"hide_rule_default_penalty"`
+	BoostRuleDefaultWeight float64  `json:"boost_rule_default_weight"`
 	CullUnreadDays         int      `json:"cull_unread_days"`
 	CullMaxScore           float64  `json:"cull_max_score"`
+	OutputFeedLimit        int      `json:"output_feed_limit"`
+	FeverAPIEnabled        bool     `json:"fever_api_enabled"`
+	RecencyHalfLifeHours   float64  `json:"recency_half_life_hours"`
+	StorySimilarity        float64  `json:"story_similarity"`
+	StoryWindowDays        int      `json:"story_window_days"`
+	ResolveCanonical       bool     `json:"resolve_canonical"`
+	ResolveMaxPerRun       int      `json:"resolve_max_per_run"`
+	ResolveTimeoutSec      int      `json:"resolve_timeout_sec"`
+	ResolveMaxBytes        int64    `json:"resolve_max_bytes"`
+	EnrichEnabled          bool     `json:"enrich_enabled"`
+	EnrichMinScore         float64  `json:"enrich_min_score"`
+	EnrichConcurrency      int      `json:"enrich_concurrency"`
+	EnrichRequestsPerMin   int      `json:"enrich_requests_per_minute"`
 }
 
 func defaultConfig() Config {
@@ -54,6 +73,9 @@ func defaultConfig() Config {
 		DatabasePath:           "discover.db",
 		DailyIngestTime:        "07:30",
 		IngestIntervalMinutes:  120,
+		IngestCron:             "",
+		IngestTimes:            []string{},
+		IngestTimezone:         "",
 		SearxngInstances:       []string{"http://localhost:8888"},
 		PerQueryDelaySeconds:   5,
 		PerQueryJitterSeconds:  5,
@@ -66,8 +88,22 @@ func defaultConfig() Config {
 		AutoHideBelowScore:     1,
 		DedupeTitleKeyChars:    50,
 		HideRuleDefaultPenalty: 10,
+		BoostRuleDefaultWeight: 3,
 		CullUnreadDays:         30,
 		CullMaxScore:           0,
+		OutputFeedLimit:        30,
+		FeverAPIEnabled:        false,
+		RecencyHalfLifeHours:   0,
+		StorySimilarity:        0.4,
+		StoryWindowDays:        3,
+		ResolveCanonical:       false,
+		ResolveMaxPerRun:       30,
+		ResolveTimeoutSec:      10,
+		ResolveMaxBytes:        512 << 10,
+		EnrichEnabled:          false,
+		EnrichMinScore:         1,
+		EnrichConcurrency:      2,
+		EnrichRequestsPerMin:   30,
 	}
 }
 
@@ -121,9 +157,6 @@ func (c Config) Validate() error {
 	if c.UserSecret == "" || c.UserSecret == defaultUserSecret {
 		return errors.New("user_secret must be set to a non-default value")
 	}
-	if len(c.SearxngInstances) == 0 {
-		return errors.New("at least one searxng_instances entry is required")
-	}
 	if c.PerQueryDelaySeconds < 0 || c.PerQueryDelaySeconds > 3600 {
 		return errors.New("per_query_delay_seconds out of range")
 	}
@@ -139,24 +172,68 @@ func (c Config) Validate() error {
 	if c.IngestIntervalMinutes > 0 && c.IngestIntervalMinutes < 5 {
 		return errors.New("ingest_interval_minutes must be >=5 when enabled")
 	}
+	if _, err := c.SchedulePlan(); err != nil {
+		return err
+	}
 	if c.FeedMinScore < -100 || c.FeedMinScore > 1000 {
 		return errors.New("feed_min_score out of range")
 	}
 	if c.HideRuleDefaultPenalty <= 0 || c.HideRuleDefaultPenalty > 1000 {
 		return errors.New("hide_rule_default_penalty must be >0 and <=1000")
 	}
+	if c.BoostRuleDefaultWeight <= 0 || c.BoostRuleDefaultWeight > 1000 {
+		return errors.New("boost_rule_default_weight must be >0 and <=1000")
+	}
 	if c.AutoHideBelowScore < -100 || c.AutoHideBelowScore > 1000 {
 		return errors.New("auto_hide_below_score out of range")
 	}
 	if c.DedupeTitleKeyChars < 10 || c.DedupeTitleKeyChars > 200 {
 		return errors.New("dedupe_title_key_chars must be 10..200")
 	}
+	if c.OutputFeedLimit <= 0 || c.OutputFeedLimit > 200 {
+		return errors.New("output_feed_limit must be 1..200")
+	}
+	if c.RecencyHalfLifeHours < 0 || c.RecencyHalfLifeHours > 8760 {
+		return errors.New("recency_half_life_hours must be 0..8760")
+	}
+	if c.StorySimilarity < 0 || c.StorySimilarity > 1 {
+		return errors.New("story_similarity must be 0..1")
+	}
+	if c.StoryWindowDays < 1 || c.StoryWindowDays > 30 {
+		return errors.New("story_window_days must be 1..30")
+	}
+	if c.ResolveMaxPerRun < 1 || c.ResolveMaxPerRun > 1000 {
+		return errors.New("resolve_max_per_run must be 1..1000")
+	}
+	if c.ResolveTimeoutSec < 1 || c.ResolveTimeoutSec > 120 {
+		return errors.New("resolve_timeout_sec must be 1..120")
+	}
+	if c.ResolveMaxBytes < 4<<10 || c.ResolveMaxBytes > 16<<20 {
+		return errors.New("resolve_max_bytes must be 4096..16777216")
+	}
+	if c.EnrichConcurrency < 1 || c.EnrichConcurrency > 16 {
+		return errors.New("enrich_concurrency must be 1..16")
+	}
+	if c.EnrichRequestsPerMin < 1 || c.EnrichRequestsPerMin > 600 {
+		return errors.New("enrich_requests_per_minute must be 1..600")
+	}
 	if c.MaxBodyBytes <= 0 {
 		return errors.New("max_body_bytes must be positive")
 	}
 	return nil
 }
 
+// SchedulePlan returns the ingest schedule selected by the config.
+func (c Config) SchedulePlan() (scheduler.Plan, error) {
+	return scheduler.NewPlan(scheduler.PlanConfig{
+		Cron:            c.IngestCron,
+		Times:           c.IngestTimes,
+		Timezone:        c.IngestTimezone,
+		IntervalMinutes: c.IngestIntervalMinutes,
+		DailyTime:       c.DailyIngestTime,
+	})
+}
+
 func MissingKeys(path string) ([]string, error) {
 	b, err := os.ReadFile(filepath.Clean(path))
 	if err != nil {
@@ -178,6 +255,9 @@ func MissingKeys(path string) ([]string, error) {
 		"database_path",
 		"daily_ingest_time",
 		"ingest_interval_minutes",
+		"ingest_cron",
+		"ingest_times",
+		"ingest_timezone",
 		"searxng_instances",
 		"per_query_delay_seconds",
 		"per_query_jitter_seconds",
@@ -190,8 +270,22 @@ func MissingKeys(path string) ([]string, error) {
 		"auto_hide_below_score",
 		"dedupe_title_key_chars",
 		"hide_rule_default_penalty",
+		"boost_rule_default_weight",
 		"cull_unread_days",
 		"cull_max_score",
+		"output_feed_limit",
+		"fever_api_enabled",
+		"recency_half_life_hours",
+		"story_similarity",
+		"story_window_days",
+		"resolve_canonical",
+		"resolve_max_per_run",
+		"resolve_timeout_sec",
+		"resolve_max_bytes",
+		"enrich_enabled",
+		"enrich_min_score",
+		"enrich_concurrency",
+		"enrich_requests_per_minute",
 	}
 	missing := make([]string, 0, len(expected))
 	for _, key := range expected {
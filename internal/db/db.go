@@ -75,9 +75,116 @@ func migrate(db *sql.DB) error {
 			value TEXT NOT NULL,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);`,
+		`CREATE TABLE IF NOT EXISTS feed_tokens (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			label TEXT NOT NULL DEFAULT '',
+			token_hash TEXT NOT NULL UNIQUE,
+			prefix TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_used_at DATETIME
+		);`,
+		`CREATE TABLE IF NOT EXISTS stories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL DEFAULT '',
+			article_count INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS ingest_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_id INTEGER NOT NULL DEFAULT 0,
+			source TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT 'running',
+			started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			finished_at DATETIME,
+			error TEXT NOT NULL DEFAULT '',
+			topics INTEGER NOT NULL DEFAULT 0,
+			failed_topics INTEGER NOT NULL DEFAULT 0,
+			fetched_entries INTEGER NOT NULL DEFAULT 0,
+			new_articles INTEGER NOT NULL DEFAULT 0,
+			updated_articles INTEGER NOT NULL DEFAULT 0
+		);`,
+		`CREATE TABLE IF NOT EXISTS ingest_run_topics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			topic_id INTEGER NOT NULL,
+			kind TEXT NOT NULL DEFAULT '',
+			query TEXT NOT NULL DEFAULT '',
+			instance TEXT NOT NULL DEFAULT '',
+			results INTEGER NOT NULL DEFAULT 0,
+			new_articles INTEGER NOT NULL DEFAULT 0,
+			updated_articles INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS searx_instances (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT NOT NULL UNIQUE,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			priority INTEGER NOT NULL DEFAULT 0,
+			delay_seconds INTEGER NOT NULL DEFAULT 0,
+			extra_params TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS url_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			domain TEXT NOT NULL UNIQUE,
+			keep_params TEXT NOT NULL DEFAULT '',
+			strip_params TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS url_aliases (
+			url_hash TEXT PRIMARY KEY,
+			article_id INTEGER NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS searx_instance_health (
+			url TEXT PRIMARY KEY,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			successes INTEGER NOT NULL DEFAULT 0,
+			failures INTEGER NOT NULL DEFAULT 0,
+			empty_results INTEGER NOT NULL DEFAULT 0,
+			consecutive_failures INTEGER NOT NULL DEFAULT 0,
+			avg_latency_ms REAL NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			last_error_at DATETIME,
+			last_success_at DATETIME,
+			blocked_until DATETIME,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS article_status_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id INTEGER NOT NULL,
+			article_id INTEGER NOT NULL,
+			old_status TEXT NOT NULL,
+			new_status TEXT NOT NULL,
+			score_delta REAL NOT NULL DEFAULT 0,
+			source TEXT NOT NULL DEFAULT '',
+			prev_changed_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			undone_at DATETIME
+		);`,
+		`CREATE TABLE IF NOT EXISTS article_score_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			article_id INTEGER NOT NULL,
+			component TEXT NOT NULL,
+			ref_id INTEGER NOT NULL DEFAULT 0,
+			detail TEXT NOT NULL DEFAULT '',
+			delta REAL NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_status_score_pub ON articles(status, score DESC, published_at DESC);`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_ingested ON articles(ingested_at);`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_published ON articles(published_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_score_events_article ON article_score_events(article_id, id);`,
+		`CREATE INDEX IF NOT EXISTS idx_run_topics_run ON ingest_run_topics(run_id, id);`,
+		`CREATE INDEX IF NOT EXISTS idx_run_topics_topic ON ingest_run_topics(topic_id, id);`,
+		`CREATE INDEX IF NOT EXISTS idx_status_events_action ON article_status_events(action_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_status_events_article ON article_status_events(article_id, id);`,
+		`CREATE INDEX IF NOT EXISTS idx_url_aliases_article ON url_aliases(article_id);`,
 	}
 	for _, stmt := range stmts {
 		if _, err := db.Exec(stmt); err != nil {
@@ -87,13 +194,171 @@ func migrate(db *sql.DB) error {
 	if err := ensureColumn(db, "negative_rules", "applied_count", "INTEGER NOT NULL DEFAULT 0"); err != nil {
 		return err
 	}
+	if err := ensureColumn(db, "topics", "kind", "TEXT NOT NULL DEFAULT 'search'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "half_life_hours", "REAL NOT NULL DEFAULT -1"); err != nil {
+		return err
+	}
+	// 0 used to mean "inherit the global half-life"; it now turns decay off.
+	if err := migrateOnce(db, "migration_topic_half_life_inherit", func() error {
+		_, err := db.Exec(`UPDATE topics SET half_life_hours=-1 WHERE half_life_hours=0`)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "categories", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "engines", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "language", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "time_ranges", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "pages", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "min_interval_minutes", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "topics", "last_fetched_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "searx_instances", "provider", "TEXT NOT NULL DEFAULT 'searxng'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "searx_instances", "api_key", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "searx_instances", "response_format", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "negative_rules", "kind", "TEXT NOT NULL DEFAULT 'penalty'"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "story_id", "INTEGER"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "minhash", "BLOB"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "canonical_checked_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "site_name", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	hadPublishedEstimated, err := hasColumn(db, "articles", "published_estimated")
+	if err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "published_estimated", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if !hadPublishedEstimated {
+		// Undated articles used to get the ingest time as published time, and
+		// every later hit moved both along together.
+		if _, err := db.Exec(`UPDATE articles SET published_estimated=1 WHERE published_at = ingested_at`); err != nil {
+			return err
+		}
+	}
+	if err := ensureColumn(db, "articles", "enriched_at", "DATETIME"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_articles_story ON articles(story_id);`); err != nil {
+		return err
+	}
+	hadStatusChanged, err := hasColumn(db, "articles", "status_changed_at")
+	if err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "articles", "status_changed_at", "DATETIME"); err != nil {
+		return err
+	}
+	if !hadStatusChanged {
+		// Best guess for existing rows: the last time the row was touched.
+		if _, err := db.Exec(`UPDATE articles SET status_changed_at=updated_at WHERE status_changed_at IS NULL`); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_articles_status_changed ON articles(status, status_changed_at DESC, id DESC);`); err != nil {
+		return err
+	}
+	return migrateSearch(db)
+}
+
+// migrateSearch keeps an external-content FTS5 index over article title,
+// content and source domain in sync via triggers. A freshly created index is
+// rebuilt from existing articles.
+func migrateSearch(db *sql.DB) error {
+	var existing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='articles_fts'`).Scan(&existing); err != nil {
+		return err
+	}
+	stmts := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5(
+			title, content, source_domain,
+			content='articles', content_rowid='id',
+			tokenize='unicode61 remove_diacritics 2'
+		);`,
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_ai AFTER INSERT ON articles BEGIN
+			INSERT INTO articles_fts(rowid, title, content, source_domain) VALUES (new.id, new.title, new.content, new.source_domain);
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_ad AFTER DELETE ON articles BEGIN
+			INSERT INTO articles_fts(articles_fts, rowid, title, content, source_domain) VALUES ('delete', old.id, old.title, old.content, old.source_domain);
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS articles_fts_au AFTER UPDATE OF title, content, source_domain ON articles BEGIN
+			INSERT INTO articles_fts(articles_fts, rowid, title, content, source_domain) VALUES ('delete', old.id, old.title, old.content, old.source_domain);
+			INSERT INTO articles_fts(rowid, title, content, source_domain) VALUES (new.id, new.title, new.content, new.source_domain);
+		END;`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if existing == 0 {
+		if _, err := db.Exec(`INSERT INTO articles_fts(articles_fts) VALUES ('rebuild')`); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// migrateOnce runs a one-off data migration and records key in app_settings,
+// so it is skipped on later starts.
+func migrateOnce(db *sql.DB, key string, fn func() error) error {
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM app_settings WHERE key=?`, key).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT INTO app_settings(key, value, updated_at) VALUES(?, '1', CURRENT_TIMESTAMP)`, key)
+	return err
+}
+
 func ensureColumn(db *sql.DB, table, column, columnDDL string) error {
+	ok, err := hasColumn(db, table, column)
+	if err != nil || ok {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + columnDDL)
+	return err
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
 	rows, err := db.Query(`PRAGMA table_info(` + table + `)`)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -104,15 +369,11 @@ func ensureColumn(db *sql.DB, table, column, columnDDL string) error {
 		var dflt sql.NullString
 		var pk int
 		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
-			return err
+			return false, err
 		}
 		if name == column {
-			return nil
+			return true, nil
 		}
 	}
-	if err := rows.Err(); err != nil {
-		return err
-	}
-	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + columnDDL)
-	return err
+	return false, rows.Err()
 }
@@ -0,0 +1,96 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"discover/internal/model"
+)
+
+// braveProvider queries a Brave Search style web/news JSON API: the instance
+// URL is the API base (https://api.search.brave.com/res/v1) and the API key is
+// sent as X-Subscription-Token. The news category uses /news/search, anything
+// else /web/search.
+type braveProvider struct{}
+
+func (braveProvider) Kind() string { return model.ProviderBrave }
+
+const (
+	braveMaxCount  = 20
+	braveMaxOffset = 9
+)
+
+var braveFreshness = map[string]string{"day": "pd", "week": "pw", "month": "pm", "year": "py"}
+
+func (braveProvider) Request(ctx context.Context, inst model.SearxInstance, q SearchQuery) (*http.Request, error) {
+	if inst.APIKey == "" {
+		return nil, errors.New("brave provider needs an API key")
+	}
+	params := url.Values{}
+	params.Set("q", q.Query)
+	params.Set("count", strconv.Itoa(min(q.Count, braveMaxCount)))
+	params.Set("offset", strconv.Itoa(min(q.Page-1, braveMaxOffset)))
+	if f, ok := braveFreshness[q.TimeRange]; ok {
+		params.Set("freshness", f)
+	}
+	if lang := baseLanguage(q.Language); lang != "" {
+		params.Set("search_lang", lang)
+	}
+	endpoint := "/web/search"
+	if q.Category == "news" {
+		endpoint = "/news/search"
+	}
+	target, err := endpointURL(inst, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newSearchRequest(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Subscription-Token", inst.APIKey)
+	return req, nil
+}
+
+type braveResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	PageAge     string `json:"page_age"`
+	Thumbnail   struct {
+		Src string `json:"src"`
+	} `json:"thumbnail"`
+}
+
+// braveResponse covers both endpoints: web results are nested under "web",
+// news results are top-level.
+type braveResponse struct {
+	Web struct {
+		Results []braveResult `json:"results"`
+	} `json:"web"`
+	Results []braveResult `json:"results"`
+}
+
+func (braveProvider) Parse(body []byte) ([]Entry, error) {
+	var parsed braveResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	results := append(parsed.Web.Results, parsed.Results...)
+	out := make([]Entry, 0, len(results))
+	for _, r := range results {
+		out = append(out, Entry{
+			URL:       r.URL,
+			Title:     htmlToText(r.Title),
+			Content:   htmlToText(r.Description),
+			Thumbnail: r.Thumbnail.Src,
+			Engines:   1,
+			Published: parsePublished(r.PageAge, ""),
+		})
+	}
+	return out, nil
+}
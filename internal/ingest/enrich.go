@@ -0,0 +1,232 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discover/internal/model"
+	"discover/internal/store"
+)
+
+const (
+	// enrichWindowHours limits enrichment to recently ingested articles.
+	enrichWindowHours  = 48
+	enrichBatchSize    = 50
+	enrichPollInterval = 10 * time.Minute
+	siteNameMaxRunes   = 100
+)
+
+// StartEnrichment runs the enrichment worker until ctx is done, if enabled.
+// The worker fills thumbnails, dates, snippets and site names from the
+// OpenGraph, Twitter Card and JSON-LD metadata of article pages. It wakes
+// after every ingest run and every enrichPollInterval, and fetches pages on
+// its own goroutines at enrich_requests_per_minute, so ingest never waits
+// for it.
+func (s *Service) StartEnrichment(ctx context.Context) {
+	if !s.cfg.EnrichEnabled {
+		return
+	}
+	go func() {
+		for {
+			s.enrichPending(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.enrichWake:
+			case <-time.After(enrichPollInterval):
+			}
+		}
+	}()
+}
+
+// wakeEnrichment asks the worker to look for new candidates; it never
+// blocks.
+func (s *Service) wakeEnrichment() {
+	select {
+	case s.enrichWake <- struct{}{}:
+	default:
+	}
+}
+
+// enrichPending works through candidates until none are left. Every
+// candidate is marked enriched, also when its page cannot be fetched.
+func (s *Service) enrichPending(ctx context.Context) {
+	limit := time.NewTicker(time.Minute / time.Duration(s.cfg.EnrichRequestsPerMin))
+	defer limit.Stop()
+	for ctx.Err() == nil {
+		articles, err := s.store.ListEnrichCandidates(ctx, s.cfg.EnrichMinScore, enrichWindowHours, enrichBatchSize)
+		if err != nil {
+			log.Printf("enrich: candidates error: %v", err)
+			return
+		}
+		if len(articles) == 0 {
+			return
+		}
+		start := time.Now()
+		jobs := make(chan model.Article)
+		var mu sync.Mutex
+		enriched, failed, marked := 0, 0, 0
+		var wg sync.WaitGroup
+		for range s.cfg.EnrichConcurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for a := range jobs {
+					fetched, saved := s.enrichArticle(ctx, a)
+					mu.Lock()
+					if fetched && saved {
+						enriched++
+					} else {
+						failed++
+					}
+					if saved {
+						marked++
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+	feed:
+		for _, a := range articles {
+			select {
+			case <-ctx.Done():
+				break feed
+			case <-limit.C:
+			}
+			select {
+			case <-ctx.Done():
+				break feed
+			case jobs <- a:
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		log.Printf("enrich: %d article(s) enriched, %d failed, took %s", enriched, failed, time.Since(start).Round(time.Millisecond))
+		if marked == 0 {
+			// Nothing could be saved; listing again would return the same batch.
+			return
+		}
+	}
+}
+
+// enrichArticle reports whether the page was fetched and whether the
+// article was saved as enriched.
+func (s *Service) enrichArticle(ctx context.Context, a model.Article) (fetched, saved bool) {
+	var in store.EnrichInput
+	page, err := s.fetchArticlePage(ctx, a.URL, time.Duration(s.cfg.ResolveTimeoutSec)*time.Second, s.cfg.ResolveMaxBytes)
+	if err != nil {
+		log.Printf("enrich: id=%d url=%q error=%v", a.ID, a.URL, err)
+	} else {
+		fetched = true
+		in = pageEnrichment(page, time.Now())
+	}
+	// A failed fetch is marked too, so it is not retried forever.
+	if err := s.store.ApplyEnrichment(context.WithoutCancel(ctx), a.ID, in); err != nil {
+		log.Printf("enrich: id=%d update error: %v", a.ID, err)
+		return fetched, false
+	}
+	return fetched, true
+}
+
+// pageEnrichment reads the metadata of an article page: OpenGraph and
+// article:* first, then Twitter Card and plain meta tags, then JSON-LD.
+// Dates in the future or before 1995 are ignored.
+func pageEnrichment(page articlePage, now time.Time) store.EnrichInput {
+	m := page.Meta
+	in := store.EnrichInput{
+		ThumbnailURL: firstNonEmpty(m["og:image:secure_url"], m["og:image"], m["og:image:url"], m["twitter:image"], m["twitter:image:src"]),
+		Description:  firstNonEmpty(m["og:description"], m["twitter:description"], m["description"]),
+		SiteName:     firstNonEmpty(m["og:site_name"], m["application-name"]),
+		PublishedAt:  parseFeedDate(m["article:published_time"], m["og:article:published_time"], m["datepublished"], m["pubdate"], m["publish-date"], m["parsely-pub-date"], m["dc.date.issued"], m["dc.date"], m["date"]),
+	}
+	for _, raw := range page.JSONLD {
+		ld, ok := ldArticle(raw)
+		if !ok {
+			continue
+		}
+		in.ThumbnailURL = firstNonEmpty(in.ThumbnailURL, ldImage(ld["image"]))
+		in.Description = firstNonEmpty(in.Description, ldString(ld["description"]))
+		if publisher, ok := ld["publisher"].(map[string]any); ok {
+			in.SiteName = firstNonEmpty(in.SiteName, ldString(publisher["name"]))
+		}
+		if in.PublishedAt.IsZero() {
+			in.PublishedAt = parseFeedDate(ldString(ld["datePublished"]), ldString(ld["dateCreated"]))
+		}
+	}
+	if u := absoluteURL(page.FinalURL, in.ThumbnailURL); u != nil {
+		in.ThumbnailURL = u.String()
+	} else {
+		in.ThumbnailURL = ""
+	}
+	in.Description = truncateRunes(strings.TrimSpace(htmlToText(in.Description)), feedContentMaxRunes)
+	in.SiteName = truncateRunes(strings.TrimSpace(in.SiteName), siteNameMaxRunes)
+	if in.PublishedAt.After(now.Add(24*time.Hour)) || in.PublishedAt.Year() < 1995 {
+		in.PublishedAt = time.Time{}
+	}
+	return in
+}
+
+// ldArticle returns the first node of a JSON-LD document that describes
+// an article: its @type names an article or posting, or it has a
+// datePublished. Top-level arrays and @graph lists are searched.
+func ldArticle(raw string) (map[string]any, bool) {
+	var doc any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &doc); err != nil {
+		return nil, false
+	}
+	nodes := []any{doc}
+	for i := 0; i < len(nodes); i++ {
+		switch n := nodes[i].(type) {
+		case []any:
+			nodes = append(nodes, n...)
+		case map[string]any:
+			if graph, ok := n["@graph"].([]any); ok {
+				nodes = append(nodes, graph...)
+			}
+			if _, ok := n["datePublished"]; ok || ldIsArticle(n["@type"]) {
+				return n, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func ldIsArticle(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return strings.HasSuffix(v, "Article") || strings.HasSuffix(v, "Posting")
+	case []any:
+		for _, x := range v {
+			if ldIsArticle(x) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ldString(v any) string {
+	s, _ := v.(string)
+	return strings.TrimSpace(s)
+}
+
+// ldImage reads an image given as a URL, an ImageObject or a list of either.
+func ldImage(v any) string {
+	switch img := v.(type) {
+	case string:
+		return strings.TrimSpace(img)
+	case map[string]any:
+		return ldString(img["url"])
+	case []any:
+		for _, x := range img {
+			if u := ldImage(x); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
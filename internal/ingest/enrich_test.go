@@ -0,0 +1,147 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"discover/internal/store"
+)
+
+func TestPageEnrichment(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		head string
+		want store.EnrichInput
+	}{
+		{
+			name: "OpenGraph before Twitter Card and meta",
+			head: `<meta name="twitter:image" content="https://cdn.example.com/tw.jpg">
+				<meta property="og:image" content="https://cdn.example.com/og.jpg">
+				<meta name="twitter:description" content="Twitter text">
+				<meta property="og:description" content="OpenGraph &lt;b&gt;text&lt;/b&gt;">
+				<meta name="description" content="Plain text">
+				<meta property="og:site_name" content="Example News">
+				<meta property="article:published_time" content="2026-10-16T08:30:00+02:00">`,
+			want: store.EnrichInput{
+				ThumbnailURL: "https://cdn.example.com/og.jpg",
+				Description:  "OpenGraph text",
+				SiteName:     "Example News",
+				PublishedAt:  time.Date(2026, 10, 16, 6, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Twitter Card before meta description",
+			head: `<meta name="description" content="Plain text">
+				<meta name="twitter:description" content="Twitter text">
+				<meta name="twitter:image:src" content="https://cdn.example.com/tw.jpg">`,
+			want: store.EnrichInput{
+				ThumbnailURL: "https://cdn.example.com/tw.jpg",
+				Description:  "Twitter text",
+			},
+		},
+		{
+			name: "meta tags before JSON-LD",
+			head: `<meta property="og:description" content="OpenGraph text">
+				<meta name="pubdate" content="2026-10-15">
+				<script type="application/ld+json">{"@type":"NewsArticle","description":"LD text",
+					"image":"https://cdn.example.com/ld.jpg","datePublished":"2026-10-01T00:00:00Z",
+					"publisher":{"@type":"Organization","name":"LD News"}}</script>`,
+			want: store.EnrichInput{
+				ThumbnailURL: "https://cdn.example.com/ld.jpg",
+				Description:  "OpenGraph text",
+				SiteName:     "LD News",
+				PublishedAt:  time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "JSON-LD @graph with ImageObject and relative URL",
+			head: `<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+					{"@type":"WebSite","name":"Site","image":"/site.png"},
+					{"@type":"NewsArticle","description":"Graph text","datePublished":"2026-10-14T09:00:00Z",
+					 "image":{"@type":"ImageObject","url":"/img/story.jpg","width":1200}}]}</script>`,
+			want: store.EnrichInput{
+				ThumbnailURL: "https://news.example.com/img/story.jpg",
+				Description:  "Graph text",
+				PublishedAt:  time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "JSON-LD array with a list of images",
+			head: `<script type="application/ld+json">[{"@type":"BreadcrumbList"},
+					{"@type":["Thing","BlogPosting"],"image":[{"@type":"ImageObject","url":""},"img/first.jpg","img/second.jpg"],
+					 "dateCreated":"2026-10-13"}]</script>`,
+			want: store.EnrichInput{
+				ThumbnailURL: "https://news.example.com/2026/img/first.jpg",
+				PublishedAt:  time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "protocol-relative image",
+			head: `<meta property="og:image" content="//cdn.example.com/a.jpg">`,
+			want: store.EnrichInput{ThumbnailURL: "https://cdn.example.com/a.jpg"},
+		},
+		{
+			name: "unusable image",
+			head: `<meta property="og:image" content="data:image/gif;base64,R0lGOD">`,
+			want: store.EnrichInput{},
+		},
+		{
+			name: "future date dropped",
+			head: `<meta property="article:published_time" content="2026-10-19T12:00:00Z">`,
+			want: store.EnrichInput{},
+		},
+		{
+			name: "date within a day ahead kept",
+			head: `<meta property="article:published_time" content="2026-10-18T06:00:00Z">`,
+			want: store.EnrichInput{PublishedAt: time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "pre-1995 date dropped",
+			head: `<meta property="article:published_time" content="1970-01-01T00:00:00Z">
+				<script type="application/ld+json">{"@type":"Article","datePublished":"1994-12-31"}</script>`,
+			want: store.EnrichInput{},
+		},
+		{
+			name: "non-article JSON-LD ignored",
+			head: `<script type="application/ld+json">{"@type":"Organization","image":"/logo.png","description":"About us"}</script>`,
+			want: store.EnrichInput{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := scanHead("<html><head>" + tt.head + "</head><body></body></html>")
+			page.FinalURL = mustParseURL(t, "https://news.example.com/2026/story.html")
+			got := pageEnrichment(page, now)
+			if got.ThumbnailURL != tt.want.ThumbnailURL || got.Description != tt.want.Description ||
+				got.SiteName != tt.want.SiteName || !got.PublishedAt.Equal(tt.want.PublishedAt) {
+				t.Errorf("pageEnrichment = %+v\nwant             %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDArticle(t *testing.T) {
+	tests := []struct {
+		raw      string
+		wantDesc string
+		wantOK   bool
+	}{
+		{`{"@type":"NewsArticle","description":"a"}`, "a", true},
+		{`{"@type":"ReportageNewsArticle","description":"a"}`, "a", true},
+		{`{"@type":"JobPosting","description":"a"}`, "a", true},
+		{`{"@type":"WebPage","datePublished":"2026-10-01","description":"a"}`, "a", true},
+		{`[{"@type":"WebSite","description":"x"},{"@type":"Article","description":"a"}]`, "a", true},
+		{`{"@graph":[{"@type":"Person","description":"x"},{"@type":["Article"],"description":"a"}]}`, "a", true},
+		{`[[{"@type":"Article","description":"a"}]]`, "a", true},
+		{`{"@type":"Organization","description":"x"}`, "", false},
+		{`not json`, "", false},
+		{``, "", false},
+	}
+	for _, tt := range tests {
+		node, ok := ldArticle(tt.raw)
+		if ok != tt.wantOK || ldString(node["description"]) != tt.wantDesc {
+			t.Errorf("ldArticle(%s) = %v, %v; want description %q, %v", tt.raw, node, ok, tt.wantDesc, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"discover/internal/model"
+)
+
+const feedContentMaxRunes = 1000
+
+// feedSource polls RSS 2.0, RSS 1.0 (RDF) and Atom feeds. The topic query
+// holds the feed URL.
+type feedSource struct {
+	client *http.Client
+}
+
+func (src *feedSource) Kind() string { return model.TopicKindFeed }
+
+func (src *feedSource) Fetch(ctx context.Context, topic model.Topic) ([]Entry, error) {
+	feedURL := strings.TrimSpace(topic.Query)
+	base, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	req.Header.Set("User-Agent", "discover/0.3")
+	resp, err := src.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	entries, err := parseFeed(body, base)
+	if err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	for i := range entries {
+		entries[i].Origin = base.Host
+	}
+	return entries, nil
+}
+
+type feedDoc struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title        string     `xml:"title"`
+	Link         string     `xml:"link"`
+	GUID         string     `xml:"guid"`
+	Description  string     `xml:"description"`
+	Encoded      string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate      string     `xml:"pubDate"`
+	DCDate       string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Enclosures   []mediaRef `xml:"enclosure"`
+	MediaThumbs  []mediaRef `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaContent []mediaRef `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+type atomEntry struct {
+	Title       atomText   `xml:"title"`
+	Links       []atomLink `xml:"link"`
+	ID          string     `xml:"id"`
+	Summary     atomText   `xml:"summary"`
+	Content     atomText   `xml:"content"`
+	Published   string     `xml:"published"`
+	Updated     string     `xml:"updated"`
+	MediaThumbs []mediaRef `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type mediaRef struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+func (t atomText) plain() string {
+	if strings.EqualFold(t.Type, "xhtml") {
+		return htmlToText(t.Inner)
+	}
+	return htmlToText(t.Text)
+}
+
+func parseFeed(body []byte, base *url.URL) ([]Entry, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = charsetReader
+	var doc feedDoc
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss":
+		return rssEntries(doc.Channel.Items, base), nil
+	case "rdf":
+		return rssEntries(doc.Items, base), nil
+	case "feed":
+		return atomEntries(doc.Entries, base), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed root element %q", doc.XMLName.Local)
+	}
+}
+
+func rssEntries(items []rssItem, base *url.URL) []Entry {
+	out := make([]Entry, 0, len(items))
+	for _, it := range items {
+		link := strings.TrimSpace(it.Link)
+		if link == "" && strings.HasPrefix(strings.TrimSpace(it.GUID), "http") {
+			link = strings.TrimSpace(it.GUID)
+		}
+		link = resolveFeedLink(base, link)
+		title := cleanFeedTitle(it.Title)
+		if link == "" || title == "" {
+			continue
+		}
+		desc := firstNonEmpty(it.Description, it.Encoded)
+		thumb := firstMediaURL(it.MediaThumbs, it.MediaContent, imageEnclosures(it.Enclosures))
+		if thumb == "" {
+			thumb = firstImageSrc(firstNonEmpty(it.Encoded, it.Description))
+		}
+		out = append(out, Entry{
+			URL:       link,
+			Title:     title,
+			Content:   truncateRunes(htmlToText(desc), feedContentMaxRunes),
+			Thumbnail: resolveFeedLink(base, thumb),
+			Engines:   1,
+			Published: parseFeedDate(it.PubDate, it.DCDate),
+		})
+	}
+	return out
+}
+
+func atomEntries(entries []atomEntry, base *url.URL) []Entry {
+	out := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		link := resolveFeedLink(base, atomAlternate(e.Links))
+		title := cleanFeedTitle(e.Title.plain())
+		if link == "" || title == "" {
+			continue
+		}
+		desc := firstNonEmpty(e.Summary.plain(), e.Content.plain())
+		thumb := firstMediaURL(e.MediaThumbs)
+		if thumb == "" {
+			thumb = firstImageSrc(firstNonEmpty(e.Content.Text, e.Content.Inner, e.Summary.Text))
+		}
+		out = append(out, Entry{
+			URL:       link,
+			Title:     title,
+			Content:   truncateRunes(desc, feedContentMaxRunes),
+			Thumbnail: resolveFeedLink(base, thumb),
+			Engines:   1,
+			Published: parseFeedDate(e.Published, e.Updated),
+		})
+	}
+	return out
+}
+
+func atomAlternate(links []atomLink) string {
+	fallback := ""
+	for _, l := range links {
+		href := strings.TrimSpace(l.Href)
+		if href == "" {
+			continue
+		}
+		rel := strings.ToLower(strings.TrimSpace(l.Rel))
+		if rel == "" || rel == "alternate" {
+			return href
+		}
+		if fallback == "" && rel != "self" && rel != "enclosure" {
+			fallback = href
+		}
+	}
+	return fallback
+}
+
+func imageEnclosures(refs []mediaRef) []mediaRef {
+	out := make([]mediaRef, 0, len(refs))
+	for _, r := range refs {
+		if strings.HasPrefix(strings.ToLower(r.Type), "image/") {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func firstMediaURL(groups ...[]mediaRef) string {
+	for _, refs := range groups {
+		for _, r := range refs {
+			t := strings.ToLower(r.Type)
+			if t != "" && !strings.HasPrefix(t, "image/") {
+				continue
+			}
+			if u := strings.TrimSpace(r.URL); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+var imgSrcPattern = regexp.MustCompile(`(?i)<img[^>]+src\s*=\s*["']([^"']+)["']`)
+
+func firstImageSrc(markup string) string {
+	m := imgSrcPattern.FindStringSubmatch(markup)
+	if len(m) < 2 {
+		return ""
+	}
+	return html.UnescapeString(strings.TrimSpace(m[1]))
+}
+
+func resolveFeedLink(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if base == nil || ref.IsAbs() {
+		return ref.String()
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func parseFeedDate(values ...string) time.Time {
+	layouts := []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
+		"2 Jan 2006 15:04:05 MST",
+		time.RFC3339Nano,
+		time.RFC3339,
+		time.RFC822Z,
+		time.RFC822,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func cleanFeedTitle(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// htmlToText drops markup from feed descriptions and collapses whitespace.
+// It is deliberately naive; feed snippets only need to be readable and
+// matchable by rules.
+func htmlToText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+			b.WriteByte(' ')
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+func truncateRunes(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		b, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed charset %q", charset)
+	}
+}
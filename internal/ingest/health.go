@@ -0,0 +1,136 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"time"
+
+	"discover/internal/model"
+)
+
+const (
+	// latencySmoothing weighs the newest attempt in the latency moving average.
+	latencySmoothing = 0.3
+	// Repeated 5xx responses and timeouts back an instance off for
+	// backoffBase, doubling per further failure up to backoffMax.
+	backoffBase = time.Minute
+	backoffMax  = 2 * time.Hour
+	// minRateLimitBlock is the shortest block after a 429.
+	minRateLimitBlock = 30 * time.Second
+)
+
+// statusError is a non-2xx SearXNG response.
+type statusError struct{ code int }
+
+func (e *statusError) Error() string { return fmt.Sprintf("status %d", e.code) }
+
+// attempt is the outcome of one topic harvest against one instance.
+type attempt struct {
+	results    int
+	requests   int
+	elapsed    time.Duration
+	retryAfter time.Duration
+	err        error
+}
+
+// transient reports whether err is worth backing off for: a 5xx response or
+// a network failure such as a timeout or refused connection.
+func transient(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= 500
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// applyAttempt folds a into h.
+func applyAttempt(h *model.InstanceHealth, a attempt, now time.Time) {
+	h.Attempts++
+	if a.requests > 0 {
+		ms := float64(a.elapsed.Milliseconds()) / float64(a.requests)
+		if h.AvgLatencyMS == 0 {
+			h.AvgLatencyMS = ms
+		} else {
+			h.AvgLatencyMS += latencySmoothing * (ms - h.AvgLatencyMS)
+		}
+	}
+	// A harvest that got results counts as a success even if some of its
+	// requests failed.
+	if a.results > 0 || (a.err == nil && a.retryAfter == 0) {
+		h.Successes++
+		if a.results == 0 {
+			h.EmptyResults++
+		}
+		h.ConsecutiveFailures = 0
+		h.LastSuccessAt = now
+		h.BlockedUntil = time.Time{}
+		return
+	}
+	h.Failures++
+	h.ConsecutiveFailures++
+	h.LastErrorAt = now
+	switch {
+	case a.retryAfter > 0:
+		h.LastError = fmt.Sprintf("rate-limited (429), retry after %s", a.retryAfter.Round(time.Second))
+		h.BlockedUntil = now.Add(max(a.retryAfter, minRateLimitBlock))
+	case a.err != nil:
+		h.LastError = a.err.Error()
+		if transient(a.err) && h.ConsecutiveFailures >= 2 {
+			h.BlockedUntil = now.Add(backoffFor(h.ConsecutiveFailures))
+		}
+	}
+}
+
+func backoffFor(failures int) time.Duration {
+	d := backoffBase
+	for i := 2; i < failures && d < backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, backoffMax)
+}
+
+// instanceWeight scores an instance for selection: success rate (smoothed so
+// new instances start at 0.5), penalized for empty results and latency.
+func instanceWeight(h model.InstanceHealth) float64 {
+	success := float64(h.Successes+1) / float64(h.Attempts+2)
+	useful := 1 - 0.5*float64(h.EmptyResults)/float64(h.Successes+1)
+	speed := 1 / (1 + h.AvgLatencyMS/1000)
+	return success * useful * speed
+}
+
+// orderInstances returns instances by priority, highest first. Within a
+// priority the order is weighted random, so healthy and fast instances are
+// usually tried first while the rest still get occasional traffic to recover
+// their score.
+func (s *Service) orderInstances(instances []model.SearxInstance, health map[string]model.InstanceHealth) []model.SearxInstance {
+	keys := make(map[string]float64, len(instances))
+	for _, inst := range instances {
+		w := math.Max(instanceWeight(health[inst.URL]), 1e-6)
+		keys[inst.URL] = math.Pow(s.rand.Float64(), 1/w)
+	}
+	out := append([]model.SearxInstance(nil), instances...)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority > out[j].Priority
+		}
+		return keys[out[i].URL] > keys[out[j].URL]
+	})
+	return out
+}
+
+// recordAttempt persists the outcome of a harvest; health tracking never
+// fails a fetch.
+func (s *Service) recordAttempt(ctx context.Context, health map[string]model.InstanceHealth, base string, a attempt) {
+	h := health[base]
+	h.URL = base
+	applyAttempt(&h, a, time.Now().UTC())
+	health[base] = h
+	if err := s.store.SaveInstanceHealth(context.WithoutCancel(ctx), h); err != nil {
+		s.logf("ingest: instance health error: %v", err)
+	}
+}
@@ -2,16 +2,12 @@ package ingest
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math/rand"
 	"net/http"
-	"net/url"
-	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,52 +16,71 @@ import (
 	"discover/internal/config"
 	"discover/internal/matcher"
 	"discover/internal/model"
+	"discover/internal/scheduler"
 	"discover/internal/store"
+	"discover/internal/urlcanon"
 )
 
 type Service struct {
 	cfg           config.Config
 	store         *store.Store
 	client        *http.Client
+	pages         pageGuard
+	pageClient    *http.Client
 	rand          *rand.Rand
 	mu            sync.Mutex
-	instanceBlock map[string]time.Time
 	lastMessage   string
 	lastMessageAt time.Time
+	onProgress    func(string)
+	sources       map[string]Source
+	providers     map[string]SearchProvider
+	lastRequest   map[string]time.Time
+	enrichWake    chan struct{}
 }
 
 func New(cfg config.Config, st *store.Store) *Service {
-	return &Service{
+	s := &Service{
 		cfg:   cfg,
 		store: st,
 		client: &http.Client{
 			Timeout: 20 * time.Second,
 		},
-		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
-		instanceBlock: make(map[string]time.Time),
-	}
+		pageClient:  pageGuard{}.client(),
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		sources:     make(map[string]Source),
+		providers:   make(map[string]SearchProvider),
+		lastRequest: make(map[string]time.Time),
+		enrichWake:  make(chan struct{}, 1),
+	}
+	s.RegisterSource(&searxSource{svc: s})
+	s.RegisterSource(&feedSource{client: s.client})
+	s.RegisterProvider(searxngProvider{})
+	s.RegisterProvider(yacyProvider{})
+	s.RegisterProvider(whoogleProvider{})
+	s.RegisterProvider(braveProvider{})
+	return s
 }
 
-type searxResponse struct {
-	Query   string       `json:"query"`
-	Results []searxEntry `json:"results"`
+func (s *Service) RegisterSource(src Source) {
+	s.sources[src.Kind()] = src
 }
 
-type searxEntry struct {
-	URL           string   `json:"url"`
-	Title         string   `json:"title"`
-	Content       string   `json:"content"`
-	Thumbnail     string   `json:"thumbnail"`
-	ImgSrc        string   `json:"img_src"`
-	Engines       []string `json:"engines"`
-	ParsedURL     []string `json:"parsed_url"`
-	Score         float64  `json:"score"`
-	PublishedDate string   `json:"publishedDate"`
-	Pubdate       string   `json:"pubdate"`
+func (s *Service) sourceFor(topic model.Topic) (Source, error) {
+	kind := topic.Kind
+	if kind == "" {
+		kind = model.TopicKindSearch
+	}
+	src, ok := s.sources[kind]
+	if !ok {
+		return nil, fmt.Errorf("no ingest source for topic kind %q", kind)
+	}
+	return src, nil
 }
 
-func (s *Service) Run(ctx context.Context) error {
+func (s *Service) Run(ctx context.Context) (err error) {
 	runStart := time.Now()
+	run := s.startRunRecord(ctx)
+	defer func() { s.finishRunRecord(ctx, run, err) }()
 	topics, err := s.store.ListEnabledTopics(ctx)
 	if err != nil {
 		return err
@@ -74,75 +89,158 @@ func (s *Service) Run(ctx context.Context) error {
 		s.logf("ingest: no enabled topics; skipping")
 		return nil
 	}
+	topics = s.dueTopics(topics, runStart)
+	if len(topics) == 0 {
+		s.logf("ingest: no topics due (min_interval_minutes); skipping")
+		return nil
+	}
 	s.logf("ingest: started with %d topic(s)", len(topics))
-	rules, err := s.store.ListEnabledNegativeRules(ctx)
+	run.Topics = len(topics)
+	ruleList, err := s.store.ListEnabledNegativeRules(ctx)
 	if err != nil {
 		return err
 	}
+	rules := make([]compiledRule, 0, len(ruleList))
+	for _, r := range ruleList {
+		expr, err := matcher.Compile(r.Pattern)
+		if err != nil {
+			s.logf("ingest: skipping invalid rule id=%d pattern=%q: %v", r.ID, r.Pattern, err)
+			continue
+		}
+		rules = append(rules, compiledRule{NegativeRule: r, expr: expr})
+	}
+	urlRules, err := s.store.ListURLRules(ctx)
+	if err != nil {
+		return err
+	}
+	canon := urlcanon.New(urlRules)
 	ingestedAt := time.Now().UTC()
 	totalEntries := 0
 	failedTopics := 0
 	lastTopicErr := ""
 	ruleApplyCounts := map[int64]int64{}
+	searched := false
+	topicsDone := 0
 
+topicLoop:
 	for i, topic := range topics {
-		if i > 0 {
+		if ctx.Err() != nil {
+			break
+		}
+		outcome := model.IngestRunTopic{RunID: run.ID, TopicID: topic.ID, Kind: topic.Kind, Query: topic.Query}
+		src, err := s.sourceFor(topic)
+		if err != nil {
+			failedTopics++
+			lastTopicErr = err.Error()
+			s.logf("ingest: topic=%q error=%v", topic.Query, err)
+			outcome.Error = err.Error()
+			s.recordRunTopic(ctx, outcome)
+			topicsDone = i + 1
+			continue
+		}
+		if src.Kind() == model.TopicKindSearch && searched {
 			delay := time.Duration(s.cfg.PerQueryDelaySeconds) * time.Second
 			jitter := time.Duration(s.rand.Intn(maxInt(s.cfg.PerQueryJitterSeconds, 0)+1)) * time.Second
 			sleepFor := delay + jitter
 			s.logf("ingest: sleeping %s before next topic (%d/%d)", sleepFor.Round(time.Second), i+1, len(topics))
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				break topicLoop
 			case <-time.After(sleepFor):
 			}
 		}
+		if src.Kind() == model.TopicKindSearch {
+			searched = true
+		}
 		topicStart := time.Now()
-		entries, err := s.fetchTopic(ctx, topic.Query)
+		entries, err := src.Fetch(ctx, topic)
+		if err != nil && ctx.Err() != nil {
+			break
+		}
 		if err != nil {
 			failedTopics++
 			lastTopicErr = err.Error()
 			s.logf("ingest: topic=%q error=%v", topic.Query, err)
+			outcome.Error = err.Error()
+			outcome.DurationMS = time.Since(topicStart).Milliseconds()
+			s.recordRunTopic(ctx, outcome)
+			topicsDone = i + 1
 			continue
 		}
+		if err := s.store.MarkTopicFetched(context.WithoutCancel(ctx), topic.ID, runStart); err != nil {
+			s.logf("ingest: topic=%q last-fetched update error: %v", topic.Query, err)
+		}
 		totalEntries += len(entries)
+		outcome.Results = len(entries)
+		if len(entries) > 0 {
+			outcome.Instance = entries[0].Origin
+		}
 		for _, e := range entries {
-			norm, hash, domain, err := normalizeURL(e.URL)
+			// Finish the topic even when cancelled; upserts are quick.
+			norm, domain, err := canon.Canonical(e.URL)
 			if err != nil || e.Title == "" {
 				continue
 			}
-			penalty, matchedRuleIDs := computePenalty(rules, e.Title, e.Content, domain, e.URL)
-			for _, ruleID := range matchedRuleIDs {
-				ruleApplyCounts[ruleID]++
+			penalties := computePenalty(rules, matcher.NewDoc(e.Title, e.Content, domain, e.URL))
+			for _, p := range penalties {
+				if p.RuleID > 0 {
+					ruleApplyCounts[p.RuleID]++
+				}
 			}
-			extra := termBoost(topic.Query, e.Title, e.Content)
-			published := parsePublished(e.PublishedDate, e.Pubdate)
-			thumb := strings.TrimSpace(firstNonEmpty(e.Thumbnail, e.ImgSrc))
-			if thumb == "null" {
-				thumb = ""
+			extra := 0.0
+			if src.Kind() == model.TopicKindSearch {
+				extra = termBoost(topic.Query, e.Title, e.Content)
 			}
 			input := store.UpsertArticleInput{
 				URL:           e.URL,
 				NormalizedURL: norm,
-				URLHash:       hash,
+				URLHash:       urlcanon.Hash(norm),
 				Title:         strings.TrimSpace(e.Title),
 				Content:       strings.TrimSpace(e.Content),
-				ThumbnailURL:  thumb,
+				ThumbnailURL:  e.Thumbnail,
 				SourceDomain:  domain,
-				PublishedAt:   published,
+				PublishedAt:   e.Published,
 				IngestedAt:    ingestedAt,
 				TopicID:       topic.ID,
+				TopicQuery:    topic.Query,
 				TopicWeight:   topic.Weight,
-				Engines:       len(e.Engines),
+				Engines:       e.Engines,
 				SearxScore:    e.Score,
 				ExtraTitleHit: extra,
-				Penalty:       penalty,
+				Penalties:     penalties,
 			}
-			if err := s.store.UpsertArticleHit(ctx, input); err != nil {
+			created, err := s.store.UpsertArticleHit(context.WithoutCancel(ctx), input)
+			switch {
+			case err != nil:
 				s.logf("ingest: upsert error url=%q err=%v", e.URL, err)
+			case created:
+				outcome.NewArticles++
+			default:
+				outcome.UpdatedArticles++
 			}
 		}
-		s.logf("ingest: topic done (%d/%d) query=%q results=%d took=%s", i+1, len(topics), topic.Query, len(entries), time.Since(topicStart).Round(time.Millisecond))
+		outcome.DurationMS = time.Since(topicStart).Milliseconds()
+		s.recordRunTopic(ctx, outcome)
+		run.NewArticles += outcome.NewArticles
+		run.UpdatedArticles += outcome.UpdatedArticles
+		topicsDone = i + 1
+		s.logf("ingest: topic done (%d/%d) kind=%s query=%q results=%d took=%s", i+1, len(topics), src.Kind(), topic.Query, len(entries), time.Since(topicStart).Round(time.Millisecond))
+	}
+	runErr := ctx.Err()
+	if runErr != nil {
+		s.logf("ingest: stopped after %d/%d topic(s) (%v); keeping results stored so far", topicsDone, len(topics), runErr)
+		// Post-processing still runs so the partial results are clustered,
+		// deduped and culled like a full run.
+		ctx = context.WithoutCancel(ctx)
+	}
+	if runErr == nil {
+		s.resolveCanonical(ctx, canon)
+	}
+	storyStats, err := s.store.AssignStories(ctx, time.Duration(s.cfg.StoryWindowDays)*24*time.Hour, s.cfg.StorySimilarity)
+	if err != nil {
+		s.logf("ingest: story clustering error: %v", err)
+	} else if storyStats.Assigned > 0 {
+		s.logf("ingest: stories assigned %d article(s) (joined=%d, new=%d, similarity>=%.2f)", storyStats.Assigned, storyStats.Joined, storyStats.Created, s.cfg.StorySimilarity)
 	}
 	if s.cfg.AutoHideBelowScore > -100 {
 		hiddenCount, err := s.store.HideUnreadBelowScore(ctx, s.cfg.AutoHideBelowScore)
@@ -172,7 +270,13 @@ func (s *Service) Run(ctx context.Context) error {
 	} else if deleted > 0 {
 		s.logf("cull: deleted %d old unread low-score articles", deleted)
 	}
-	s.logf("ingest: all done in %s (topics=%d, fetched_entries=%d, failed_topics=%d)", time.Since(runStart).Round(time.Millisecond), len(topics), totalEntries, failedTopics)
+	s.wakeEnrichment()
+	run.FetchedEntries = totalEntries
+	run.FailedTopics = failedTopics
+	s.logf("ingest: all done in %s (topics=%d, fetched_entries=%d, new=%d, updated=%d, failed_topics=%d)", time.Since(runStart).Round(time.Millisecond), len(topics), totalEntries, run.NewArticles, run.UpdatedArticles, failedTopics)
+	if runErr != nil {
+		return runErr
+	}
 	if failedTopics == len(topics) {
 		if lastTopicErr == "" {
 			lastTopicErr = "unknown fetch error"
@@ -182,12 +286,90 @@ func (s *Service) Run(ctx context.Context) error {
 	return nil
 }
 
+// dueTolerance lets a run that starts slightly early against the previous
+// one (scheduler wake-up jitter) still count as a full interval later.
+const dueTolerance = time.Minute
+
+// dueTopics drops topics whose min_interval_minutes has not passed since the
+// start of the run that last fetched them. Comparing run starts keeps a daily
+// topic on a daily plan from slipping to every other day.
+func (s *Service) dueTopics(topics []model.Topic, runStart time.Time) []model.Topic {
+	due := make([]model.Topic, 0, len(topics))
+	for _, t := range topics {
+		if t.MinIntervalMinutes > 0 && !t.LastFetchedAt.IsZero() {
+			next := t.LastFetchedAt.Add(time.Duration(t.MinIntervalMinutes) * time.Minute)
+			if runStart.Add(dueTolerance).Before(next) {
+				s.logf("ingest: topic=%q not due until %s (min_interval_minutes=%d)", t.Query, next.Local().Format("2006-01-02 15:04"), t.MinIntervalMinutes)
+				continue
+			}
+		}
+		due = append(due, t)
+	}
+	return due
+}
+
+// startRunRecord persists the start of a run. Run history is bookkeeping, so
+// a failure to record it is logged and the run goes ahead unrecorded.
+func (s *Service) startRunRecord(ctx context.Context) *model.IngestRun {
+	jobID, source, ok := scheduler.JobFromContext(ctx)
+	if !ok {
+		source = "manual"
+	}
+	run := &model.IngestRun{JobID: jobID, Source: source}
+	id, err := s.store.StartIngestRun(ctx, jobID, source)
+	if err != nil {
+		s.logf("ingest: run history error: %v", err)
+		return run
+	}
+	run.ID = id
+	return run
+}
+
+func (s *Service) finishRunRecord(ctx context.Context, run *model.IngestRun, err error) {
+	if run.ID == 0 {
+		return
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		run.Status = model.RunCancelled
+		run.Error = "cancelled"
+	case err != nil:
+		run.Status = model.RunFailed
+		run.Error = err.Error()
+	default:
+		run.Status = model.RunDone
+	}
+	if err := s.store.FinishIngestRun(context.WithoutCancel(ctx), *run); err != nil {
+		s.logf("ingest: run history error: %v", err)
+	}
+}
+
+func (s *Service) recordRunTopic(ctx context.Context, outcome model.IngestRunTopic) {
+	if outcome.RunID == 0 {
+		return
+	}
+	if err := s.store.AddIngestRunTopic(context.WithoutCancel(ctx), outcome); err != nil {
+		s.logf("ingest: run history error: %v", err)
+	}
+}
+
 func (s *Service) logf(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	log.Print(msg)
 	s.mu.Lock()
 	s.lastMessage = msg
 	s.lastMessageAt = time.Now()
+	hook := s.onProgress
+	s.mu.Unlock()
+	if hook != nil {
+		hook(msg)
+	}
+}
+
+// OnProgress registers fn to receive every progress line logged by a run.
+func (s *Service) OnProgress(fn func(string)) {
+	s.mu.Lock()
+	s.onProgress = fn
 	s.mu.Unlock()
 }
 
@@ -197,59 +379,160 @@ func (s *Service) LastProgress() (string, time.Time) {
 	return s.lastMessage, s.lastMessageAt
 }
 
-func (s *Service) fetchTopic(ctx context.Context, q string) ([]searxEntry, error) {
+// harvest is the set of SearXNG requests made for a search topic: every
+// category x time range x page combination.
+type harvest struct {
+	categories []string
+	timeRanges []string
+	pages      int
+	engines    string
+	language   string
+}
+
+func harvestFor(t model.Topic) harvest {
+	h := harvest{
+		categories: []string{"news", ""},
+		timeRanges: []string{"day", "week"},
+		pages:      2,
+		engines:    strings.Join(t.Engines, ","),
+		language:   t.Language,
+	}
+	if len(t.Categories) > 0 {
+		h.categories = h.categories[:0]
+		for _, c := range t.Categories {
+			// "general" is what SearXNG searches without a category.
+			if c == "general" {
+				c = ""
+			}
+			h.categories = append(h.categories, c)
+		}
+	}
+	if len(t.TimeRanges) > 0 {
+		h.timeRanges = h.timeRanges[:0]
+		for _, r := range t.TimeRanges {
+			if r == "all" {
+				r = ""
+			}
+			h.timeRanges = append(h.timeRanges, r)
+		}
+	}
+	if t.Pages > 0 {
+		h.pages = t.Pages
+	}
+	return h
+}
+
+func (s *Service) fetchTopic(ctx context.Context, q string, h harvest) ([]Entry, string, error) {
 	var lastErr error
-	instances := append([]string(nil), s.cfg.SearxngInstances...)
-	s.rand.Shuffle(len(instances), func(i, j int) { instances[i], instances[j] = instances[j], instances[i] })
+	enabled, err := s.store.ListEnabledSearxInstances(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	health, err := s.store.InstanceHealthMap(ctx)
+	if err != nil {
+		s.logf("ingest: instance health error: %v", err)
+		health = make(map[string]model.InstanceHealth)
+	}
+	instances := s.orderInstances(enabled, health)
 	rateLimited := 0
 
-	for _, base := range instances {
-		if wait, blocked := s.blockRemaining(base); blocked {
-			lastErr = fmt.Errorf("instance %s temporarily blocked for %s after previous 429", base, wait.Round(time.Second))
+	for _, inst := range instances {
+		base := inst.URL
+		if wait := time.Until(health[base].BlockedUntil); wait > 0 {
+			lastErr = fmt.Errorf("instance %s backing off for %s after: %s", base, wait.Round(time.Second), health[base].LastError)
 			continue
 		}
-		results, retryAfter, err := s.fetchHarvestFromInstance(ctx, base, q)
-		if err == nil && len(results) > 0 {
-			return results, nil
+		results, a := s.fetchHarvestFromInstance(ctx, inst, q, h)
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		s.recordAttempt(ctx, health, base, a)
+		if len(results) > 0 {
+			return results, base, nil
 		}
-		if retryAfter > 0 {
+		if a.retryAfter > 0 {
 			rateLimited++
-			s.setBlocked(base, retryAfter)
-			lastErr = fmt.Errorf("instance %s rate-limited (429), retry after %s", base, retryAfter.Round(time.Second))
+			lastErr = fmt.Errorf("instance %s rate-limited (429), retry after %s", base, a.retryAfter.Round(time.Second))
 			continue
 		}
-		if err != nil {
-			lastErr = err
+		if a.err != nil {
+			lastErr = a.err
 		}
 	}
 	if rateLimited == len(instances) && len(instances) > 0 {
-		return nil, fmt.Errorf("all configured searx instances are rate-limited; add more instances or increase per_query_delay_seconds")
+		return nil, "", fmt.Errorf("all enabled search instances are rate-limited; add more instances or increase their delay")
+	}
+	if lastErr == nil && len(instances) == 0 {
+		lastErr = fmt.Errorf("no enabled search instances; add one in the admin UI")
 	}
 	if lastErr == nil {
-		lastErr = fmt.Errorf("no searx instance available")
+		lastErr = fmt.Errorf("no search instance available")
 	}
-	return nil, lastErr
+	return nil, "", lastErr
 }
 
-func (s *Service) fetchHarvestFromInstance(ctx context.Context, base, q string) ([]searxEntry, time.Duration, error) {
-	categories := []string{"news", ""}
-	timeRanges := []string{"day", "week"}
-	pages := []int{1, 2}
+func (s *Service) fetchHarvestFromInstance(ctx context.Context, inst model.SearxInstance, q string, h harvest) ([]Entry, attempt) {
 	const count = 50
 
-	out := make([]searxEntry, 0, 128)
+	out := make([]Entry, 0, 128)
 	seen := make(map[string]struct{}, 256)
-	var lastErr error
+	var a attempt
+	provider, err := s.providerFor(inst)
+	if err != nil {
+		a.err = err
+		return nil, a
+	}
+	sent := make(map[string]struct{})
 
-	for _, category := range categories {
-		for _, timeRange := range timeRanges {
-			for _, page := range pages {
-				results, retryAfter, err := s.fetchFromInstance(ctx, base, q, category, timeRange, page, count)
+	for _, category := range h.categories {
+		for _, timeRange := range h.timeRanges {
+			for page := 1; page <= h.pages; page++ {
+				sq := SearchQuery{
+					Query:     q,
+					Category:  category,
+					TimeRange: timeRange,
+					Engines:   h.engines,
+					Language:  h.language,
+					Page:      page,
+					Count:     count,
+				}
+				req, err := provider.Request(ctx, inst, sq)
+				if err != nil {
+					a.err = err
+					return nil, a
+				}
+				// Providers without categories or time ranges produce the
+				// same request several times.
+				if _, ok := sent[req.URL.String()]; ok {
+					continue
+				}
+				sent[req.URL.String()] = struct{}{}
+				// The delay wait is not the instance's fault, so it is left
+				// out of elapsed.
+				if err := s.waitForInstance(ctx, inst); err != nil {
+					a.err = err
+					return nil, a
+				}
+				a.requests++
+				start := time.Now()
+				results, retryAfter, err := s.fetchPage(provider, req)
+				a.elapsed += time.Since(start)
+				if fb, ok := provider.(formatFallback); ok {
+					format := fb.Format(inst)
+					if alt := fb.AltFormat(inst, err); alt != "" {
+						format = alt
+						results, retryAfter, err = s.retryFormat(ctx, provider, inst, alt, sq, err, &a)
+					}
+					if err == nil && retryAfter == 0 {
+						s.rememberFormat(ctx, &inst, format)
+					}
+				}
 				if retryAfter > 0 {
-					return nil, retryAfter, err
+					a.retryAfter, a.err = retryAfter, err
+					return nil, a
 				}
 				if err != nil {
-					lastErr = err
+					a.err = err
 					continue
 				}
 				for _, r := range results {
@@ -266,35 +549,79 @@ func (s *Service) fetchHarvestFromInstance(ctx context.Context, base, q string)
 			}
 		}
 	}
-	if len(out) == 0 && lastErr != nil {
-		return nil, 0, lastErr
+	a.results = len(out)
+	if len(out) == 0 && a.err != nil {
+		return nil, a
 	}
-	return out, 0, nil
+	return out, a
 }
 
-func (s *Service) fetchFromInstance(ctx context.Context, base, q, category, timeRange string, page, count int) ([]searxEntry, time.Duration, error) {
-	u, err := url.Parse(strings.TrimRight(base, "/"))
+// retryFormat repeats a page in format alt after inst refused its current
+// format. If that fails too, the original error is kept.
+func (s *Service) retryFormat(ctx context.Context, provider SearchProvider, inst model.SearxInstance, alt string, sq SearchQuery, refused error, a *attempt) ([]Entry, time.Duration, error) {
+	retry := inst
+	retry.ResponseFormat = alt
+	req, err := provider.Request(ctx, retry, sq)
 	if err != nil {
+		return nil, 0, refused
+	}
+	if err := s.waitForInstance(ctx, retry); err != nil {
 		return nil, 0, err
 	}
-	u.Path = path.Join(u.Path, "/search")
-	params := u.Query()
-	params.Set("q", q)
-	params.Set("time_range", timeRange)
-	params.Set("format", "json")
-	params.Set("pageno", strconv.Itoa(page))
-	params.Set("count", strconv.Itoa(count))
-	if category != "" {
-		params.Set("categories", category)
+	a.requests++
+	start := time.Now()
+	results, retryAfter, err := s.fetchPage(provider, req)
+	a.elapsed += time.Since(start)
+	if retryAfter > 0 {
+		return nil, retryAfter, err
 	}
-	u.RawQuery = params.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, fmt.Errorf("%w (format=%s also failed: %v)", refused, alt, err)
+	}
+	s.logf("ingest: instance %s refused its format (%v); switching to format=%s", inst.URL, refused, alt)
+	return results, 0, nil
+}
+
+// rememberFormat stores the response format that worked for inst; later
+// pages of the harvest use it right away.
+func (s *Service) rememberFormat(ctx context.Context, inst *model.SearxInstance, format string) {
+	if inst.ID == 0 || inst.ResponseFormat == format {
+		return
 	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "discover/0.3")
+	inst.ResponseFormat = format
+	if err := s.store.SetSearxInstanceFormat(context.WithoutCancel(ctx), inst.ID, format); err != nil {
+		s.logf("ingest: instance format error: %v", err)
+	}
+}
+
+// waitForInstance blocks until the instance's delay since the previous
+// request has passed. The slot is reserved before waiting, so concurrent
+// callers queue up behind each other.
+func (s *Service) waitForInstance(ctx context.Context, inst model.SearxInstance) error {
+	if inst.DelaySeconds <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	now := time.Now()
+	at := s.lastRequest[inst.URL].Add(time.Duration(inst.DelaySeconds) * time.Second)
+	if at.Before(now) {
+		at = now
+	}
+	s.lastRequest[inst.URL] = at
+	s.mu.Unlock()
+	if !at.After(now) {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(at.Sub(now)):
+		return nil
+	}
+}
+
+// fetchPage sends one search request and parses the response.
+func (s *Service) fetchPage(provider SearchProvider, req *http.Request) ([]Entry, time.Duration, error) {
 	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, 0, err
@@ -302,16 +629,16 @@ func (s *Service) fetchFromInstance(ctx context.Context, base, q, category, time
 	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
 	_ = resp.Body.Close()
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, retryAfterDuration(resp.Header.Get("Retry-After")), fmt.Errorf("status %d", resp.StatusCode)
+		return nil, retryAfterDuration(resp.Header.Get("Retry-After")), &statusError{code: resp.StatusCode}
 	}
 	if resp.StatusCode >= 300 {
-		return nil, 0, fmt.Errorf("status %d", resp.StatusCode)
+		return nil, 0, &statusError{code: resp.StatusCode}
 	}
-	var parsed searxResponse
-	if err := json.Unmarshal(body, &parsed); err != nil {
+	results, err := provider.Parse(body)
+	if err != nil {
 		return nil, 0, err
 	}
-	return parsed.Results, 0, nil
+	return results, 0, nil
 }
 
 func retryAfterDuration(v string) time.Duration {
@@ -335,30 +662,6 @@ func retryAfterDuration(v string) time.Duration {
 	return 2 * time.Minute
 }
 
-func (s *Service) blockRemaining(instance string) (time.Duration, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	until, ok := s.instanceBlock[instance]
-	if !ok {
-		return 0, false
-	}
-	remaining := time.Until(until)
-	if remaining <= 0 {
-		delete(s.instanceBlock, instance)
-		return 0, false
-	}
-	return remaining, true
-}
-
-func (s *Service) setBlocked(instance string, d time.Duration) {
-	if d < 30*time.Second {
-		d = 30 * time.Second
-	}
-	s.mu.Lock()
-	s.instanceBlock[instance] = time.Now().Add(d)
-	s.mu.Unlock()
-}
-
 func parsePublished(primary, secondary string) time.Time {
 	for _, v := range []string{primary, secondary} {
 		v = strings.TrimSpace(v)
@@ -374,41 +677,19 @@ func parsePublished(primary, secondary string) time.Time {
 	return time.Time{}
 }
 
-func normalizeURL(raw string) (normalized, hash, domain string, err error) {
-	u, err := url.Parse(strings.TrimSpace(raw))
-	if err != nil {
-		return "", "", "", err
-	}
-	scheme := strings.ToLower(u.Scheme)
-	if scheme != "http" && scheme != "https" {
-		return "", "", "", fmt.Errorf("unsupported URL scheme")
-	}
-	u.Fragment = ""
-	u.Host = strings.ToLower(u.Host)
-	u.Scheme = scheme
-	u.RawQuery = ""
-	if u.Path == "" {
-		u.Path = "/"
-	}
-	normalized = u.String()
-	sum := sha256.Sum256([]byte(normalized))
-	hash = hex.EncodeToString(sum[:])
-	domain = u.Hostname()
-	return normalized, hash, domain, nil
+type compiledRule struct {
+	model.NegativeRule
+	expr *matcher.Rule
 }
 
-func computePenalty(rules []model.NegativeRule, title, content, domain, articleURL string) (float64, []int64) {
-	pen := 0.0
-	matched := make([]int64, 0, 2)
+func computePenalty(rules []compiledRule, doc matcher.Doc) []store.RulePenalty {
+	matched := make([]store.RulePenalty, 0, 2)
 	for _, r := range rules {
-		if matcher.MatchRule(r.Pattern, title, content, domain, articleURL) {
-			pen += r.Penalty
-			if r.ID > 0 {
-				matched = append(matched, r.ID)
-			}
+		if r.expr.Match(doc) {
+			matched = append(matched, store.RulePenalty{RuleID: r.ID, Kind: r.Kind, Pattern: r.Pattern, Penalty: store.SignedPenalty(r.Kind, r.Penalty)})
 		}
 	}
-	return pen, matched
+	return matched
 }
 
 func termBoost(query, title, content string) float64 {
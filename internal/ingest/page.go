@@ -0,0 +1,310 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxPageRedirects bounds the HTTP redirects followed for one article page.
+const maxPageRedirects = 5
+
+// nonPublicPrefixes are address ranges that are not on the public internet
+// and that netip has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// pageGuard keeps article page fetches on the public internet. Article URLs
+// come from search results, feeds and the pages themselves, so without it
+// a result could make the server request loopback, LAN or cloud metadata
+// addresses. Tests set allowPrivate to reach httptest servers.
+type pageGuard struct {
+	allowPrivate bool
+}
+
+// client returns an HTTP client that only connects to public addresses,
+// checked after DNS resolution, and checks every redirect hop. It ignores
+// proxy settings, since a proxy would hide the address really requested.
+func (g pageGuard) client() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: g.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   20 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxPageRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxPageRedirects)
+			}
+			return g.checkURL(req.URL)
+		},
+	}
+}
+
+// checkURL rejects URLs that are not http(s) or that name a non-public
+// address or localhost. Host names are checked again once resolved.
+func (g pageGuard) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if g.allowPrivate {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("non-public host %s", host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return fmt.Errorf("non-public address %s", addr)
+	}
+	return nil
+}
+
+// control runs before every connection, on the resolved address.
+func (g pageGuard) control(network, address string, _ syscall.RawConn) error {
+	if g.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addr) {
+		return fmt.Errorf("non-public address %s", addr)
+	}
+	return nil
+}
+
+// publicAddr reports whether addr is a public unicast address: not
+// loopback, private (RFC 1918, fc00::/7), link-local (which includes the
+// 169.254.169.254 metadata service), CGNAT, multicast or otherwise reserved.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// articlePage is what the <head> of a fetched article page says about it.
+type articlePage struct {
+	// FinalURL is where redirects ended.
+	FinalURL  *url.URL
+	Canonical string
+	// Refresh is the target of a <meta http-equiv="refresh"> redirect.
+	Refresh string
+	// Meta maps lowercased meta property/name to content; the first wins.
+	Meta map[string]string
+	// JSONLD holds the bodies of application/ld+json scripts.
+	JSONLD []string
+}
+
+// fetchArticlePage GETs an article page, following redirects, and reads at
+// most maxBytes of it. Only HTML responses from public addresses are
+// accepted.
+func (s *Service) fetchArticlePage(ctx context.Context, raw string, timeout time.Duration, maxBytes int64) (articlePage, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return articlePage{}, err
+	}
+	if err := s.pages.checkURL(req.URL); err != nil {
+		return articlePage{}, err
+	}
+	req.Header.Set("Accept", "text/html, application/xhtml+xml;q=0.9")
+	req.Header.Set("User-Agent", "discover/0.3")
+	resp, err := s.pageClient.Do(req)
+	if err != nil {
+		return articlePage{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return articlePage{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+			return articlePage{}, fmt.Errorf("not an HTML page (%s)", mediaType)
+		}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil {
+		return articlePage{}, err
+	}
+	page := scanHead(string(body))
+	page.FinalURL = resp.Request.URL
+	return page, nil
+}
+
+// scanHead picks <link> and <meta> tags and JSON-LD scripts out of an HTML
+// head. It is not a full parser: tags inside scripts and comments are
+// skipped, and scanning stops at </head> or <body.
+func scanHead(doc string) articlePage {
+	page := articlePage{Meta: make(map[string]string)}
+	lower := strings.ToLower(doc)
+	for i := 0; i < len(doc); {
+		lt := strings.IndexByte(lower[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt + 1
+		if strings.HasPrefix(lower[i:], "!--") {
+			end := strings.Index(lower[i:], "-->")
+			if end < 0 {
+				break
+			}
+			i += end + 3
+			continue
+		}
+		name := tagName(lower[i:])
+		if name == "" {
+			continue
+		}
+		gt := strings.IndexByte(lower[i:], '>')
+		if gt < 0 {
+			break
+		}
+		attrs := parseAttrs(doc[i+len(name) : i+gt])
+		i += gt + 1
+		switch name {
+		case "/head", "body":
+			return page
+		case "script", "style", "noscript":
+			end := strings.Index(lower[i:], "</"+name)
+			if end < 0 {
+				return page
+			}
+			if name == "script" && strings.Contains(strings.ToLower(attrs["type"]), "ld+json") {
+				page.JSONLD = append(page.JSONLD, doc[i:i+end])
+			}
+			i += end
+		case "link":
+			if page.Canonical == "" && hasToken(attrs["rel"], "canonical") {
+				page.Canonical = attrs["href"]
+			}
+		case "meta":
+			if strings.EqualFold(attrs["http-equiv"], "refresh") && page.Refresh == "" {
+				page.Refresh = refreshTarget(attrs["content"])
+			}
+			key := strings.ToLower(firstNonEmpty(attrs["property"], attrs["name"], attrs["itemprop"]))
+			if _, seen := page.Meta[key]; key != "" && !seen {
+				page.Meta[key] = strings.TrimSpace(attrs["content"])
+			}
+		}
+	}
+	return page
+}
+
+// tagName returns the lowercased element name at the start of s, with a
+// leading / for end tags.
+func tagName(s string) string {
+	start := 0
+	if strings.HasPrefix(s, "/") {
+		start = 1
+	}
+	n := start
+	for n < len(s) && (s[n] >= 'a' && s[n] <= 'z' || n > start && s[n] >= '0' && s[n] <= '9') {
+		n++
+	}
+	if n == start {
+		return ""
+	}
+	return s[:n]
+}
+
+// parseAttrs reads name=value pairs (quoted or not) from the inside of a
+// tag. Names are lowercased and values unescaped.
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t\r\n/")
+		if s == "" {
+			return attrs
+		}
+		end := strings.IndexAny(s, " \t\r\n=/")
+		if end < 0 {
+			end = len(s)
+		}
+		name := strings.ToLower(s[:end])
+		s = strings.TrimLeft(s[end:], " \t\r\n")
+		if !strings.HasPrefix(s, "=") {
+			if _, ok := attrs[name]; !ok && name != "" {
+				attrs[name] = ""
+			}
+			continue
+		}
+		s = strings.TrimLeft(s[1:], " \t\r\n")
+		var value string
+		if s != "" && (s[0] == '"' || s[0] == '\'') {
+			closing := strings.IndexByte(s[1:], s[0])
+			if closing < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:closing+1], s[closing+2:]
+			}
+		} else {
+			end := strings.IndexAny(s, " \t\r\n")
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+		}
+		if _, ok := attrs[name]; !ok && name != "" {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+}
+
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(strings.ToLower(list)) {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshTarget returns the URL of a refresh content value such as
+// "0; url=https://example.com/a".
+func refreshTarget(content string) string {
+	_, rest, ok := strings.Cut(content, ";")
+	if !ok {
+		return ""
+	}
+	rest = strings.TrimSpace(rest)
+	if len(rest) < 4 || !strings.EqualFold(rest[:4], "url=") {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(rest[4:]), `"'`)
+}
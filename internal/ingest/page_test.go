@@ -0,0 +1,116 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestScanHead(t *testing.T) {
+	doc := `<!doctype html><html><head>
+<!-- <link rel="canonical" href="/commented-out"> -->
+<meta charset=utf-8>
+<META PROPERTY="og:url" CONTENT="https://example.com/a?x=1&amp;y=2">
+<meta property="og:url" content="https://example.com/second">
+<meta name=description content='Plain &quot;text&quot;'>
+<meta http-equiv="Refresh" content="0; URL='/next'">
+<script>document.write('<link rel="canonical" href="/from-script">')</script>
+<link rel="alternate canonical" href="/canonical">
+<script type="application/ld+json">{"@type":"NewsArticle"}</script>
+</head><body><link rel="canonical" href="/in-body"></body></html>`
+	page := scanHead(doc)
+	if page.Canonical != "/canonical" {
+		t.Errorf("Canonical = %q, want /canonical", page.Canonical)
+	}
+	if page.Refresh != "/next" {
+		t.Errorf("Refresh = %q, want /next", page.Refresh)
+	}
+	wantMeta := map[string]string{
+		"og:url":      "https://example.com/a?x=1&y=2",
+		"description": `Plain "text"`,
+	}
+	if !reflect.DeepEqual(page.Meta, wantMeta) {
+		t.Errorf("Meta = %v, want %v", page.Meta, wantMeta)
+	}
+	if len(page.JSONLD) != 1 || page.JSONLD[0] != `{"@type":"NewsArticle"}` {
+		t.Errorf("JSONLD = %q", page.JSONLD)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+		"::1":                  false,
+		"fe80::1":              false,
+		"fd00:ec2::254":        false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+	} {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestPageGuardRejectsPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head></head></html>`))
+	}))
+	defer srv.Close()
+
+	guard := pageGuard{}
+	s := &Service{pages: guard, pageClient: guard.client()}
+	if _, err := s.fetchArticlePage(context.Background(), srv.URL+"/a", testTimeout, 1<<20); err == nil {
+		t.Fatal("fetched a loopback address")
+	}
+	// A public name that resolves to loopback is caught when dialing.
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Host = "example.com"
+	if _, err := guard.client().Do(req); err == nil {
+		t.Fatal("dialed a loopback address")
+	}
+	for _, raw := range []string{"http://localhost/a", "http://169.254.169.254/latest/meta-data/", "file:///etc/passwd", "http://[::1]/"} {
+		u, _ := url.Parse(raw)
+		if err := guard.checkURL(u); err == nil {
+			t.Errorf("checkURL(%s) accepted", raw)
+		}
+	}
+}
+
+func TestPageGuardChecksRedirects(t *testing.T) {
+	check := pageGuard{}.client().CheckRedirect
+	hop := func(raw string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, raw, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	if err := check(hop("https://example.com/b"), []*http.Request{hop("https://example.com/a")}); err != nil {
+		t.Errorf("public hop rejected: %v", err)
+	}
+	if err := check(hop("http://169.254.169.254/latest/meta-data/"), []*http.Request{hop("https://example.com/a")}); err == nil {
+		t.Error("redirect to the metadata address accepted")
+	}
+	via := make([]*http.Request, maxPageRedirects)
+	for i := range via {
+		via[i] = hop("https://example.com/a")
+	}
+	if err := check(hop("https://example.com/b"), via); err == nil {
+		t.Errorf("redirect %d accepted", maxPageRedirects+1)
+	}
+}
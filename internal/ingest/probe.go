@@ -0,0 +1,97 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"discover/internal/model"
+)
+
+// Probe runs one test search against inst and reports whether it answers
+// in a format its provider can parse. SearXNG instances without "json" in
+// search.formats answer 403 or fall back to the HTML page; they are probed
+// again with rss, and a saved instance keeps the format that worked. Probes
+// are not counted in instance health.
+func (s *Service) Probe(ctx context.Context, inst model.SearxInstance, query string) model.InstanceProbe {
+	provider, err := s.providerFor(inst)
+	if err != nil {
+		return model.InstanceProbe{URL: inst.URL, Error: err.Error()}
+	}
+	fb, ok := provider.(formatFallback)
+	if !ok {
+		out, err := s.probeOnce(ctx, provider, inst, query)
+		out.JSONEnabled = err == nil
+		return out
+	}
+	out, err := s.probeOnce(ctx, provider, inst, query)
+	out.Format = fb.Format(inst)
+	out.JSONEnabled = err == nil && out.Format == formatJSON
+	alt := fb.AltFormat(inst, err)
+	if alt == "" {
+		return out
+	}
+	retry := inst
+	retry.ResponseFormat = alt
+	altOut, altErr := s.probeOnce(ctx, provider, retry, query)
+	if altErr != nil {
+		out.Error += fmt.Sprintf("; format=%s failed too: %s", alt, altOut.Error)
+		return out
+	}
+	altOut.Format = alt
+	altOut.JSONEnabled = alt == formatJSON
+	if inst.ID == 0 {
+		altOut.Error = fmt.Sprintf("format=%s refused (%s); format=%s works, ingestion switches to it after saving", out.Format, out.Error, alt)
+		return altOut
+	}
+	s.rememberFormat(ctx, &inst, alt)
+	altOut.Error = fmt.Sprintf("format=%s refused (%s); format=%s works and is now used for this instance", out.Format, out.Error, alt)
+	return altOut
+}
+
+// probeOnce sends one request; the error classifies the failure and
+// InstanceProbe.Error describes it.
+func (s *Service) probeOnce(ctx context.Context, provider SearchProvider, inst model.SearxInstance, query string) (model.InstanceProbe, error) {
+	out := model.InstanceProbe{URL: inst.URL}
+	fail := func(err error, msg string) (model.InstanceProbe, error) {
+		out.Error = msg
+		return out, err
+	}
+	req, err := provider.Request(ctx, inst, SearchQuery{Query: query, Page: 1, Count: 10})
+	if err != nil {
+		return fail(err, err.Error())
+	}
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	out.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		return fail(err, err.Error())
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	_ = resp.Body.Close()
+	out.Reachable = true
+	out.StatusCode = resp.StatusCode
+	status := &statusError{code: resp.StatusCode}
+	switch {
+	case resp.StatusCode == http.StatusForbidden && provider.Kind() == model.ProviderSearxng:
+		return fail(status, "403 Forbidden: the format is probably disabled (see search.formats in settings.yml)")
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fail(status, fmt.Sprintf("status %d: check the API key or access settings", resp.StatusCode))
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fail(status, "rate-limited (429)")
+	case resp.StatusCode >= 300:
+		return fail(status, fmt.Sprintf("status %d", resp.StatusCode))
+	}
+	results, err := provider.Parse(body)
+	if errors.Is(err, errFormatRefused) {
+		return fail(err, err.Error())
+	}
+	if err != nil {
+		return fail(err, fmt.Sprintf("response is not %s output: %v", provider.Kind(), err))
+	}
+	out.Results = len(results)
+	return out, nil
+}
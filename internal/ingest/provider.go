@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"discover/internal/model"
+)
+
+// SearchQuery is one request of a topic harvest.
+type SearchQuery struct {
+	Query     string
+	Category  string // "" = general web search
+	TimeRange string // day, week, month, year, or "" for any time
+	Engines   string
+	Language  string
+	Page      int // 1-based
+	Count     int
+}
+
+// SearchProvider speaks the HTTP API of one kind of search backend. Instances
+// pick their provider by kind.
+type SearchProvider interface {
+	Kind() string
+	// Request builds the request for one page of results. Parameters the
+	// backend has no equivalent for are dropped; identical requests within a
+	// harvest are sent only once.
+	Request(ctx context.Context, inst model.SearxInstance, q SearchQuery) (*http.Request, error)
+	// Parse maps a 2xx response body to entries.
+	Parse(body []byte) ([]Entry, error)
+}
+
+// formatFallback is implemented by providers whose backend can answer in
+// another format when the preferred one is refused.
+type formatFallback interface {
+	// Format is the response format requests for inst ask for.
+	Format(inst model.SearxInstance) string
+	// AltFormat returns the format to retry with when err means inst
+	// refused its current format, or "" otherwise.
+	AltFormat(inst model.SearxInstance, err error) string
+}
+
+func (s *Service) RegisterProvider(p SearchProvider) {
+	s.providers[p.Kind()] = p
+}
+
+func (s *Service) providerFor(inst model.SearxInstance) (SearchProvider, error) {
+	kind := inst.Provider
+	if kind == "" {
+		kind = model.ProviderSearxng
+	}
+	p, ok := s.providers[kind]
+	if !ok {
+		return nil, fmt.Errorf("no search provider %q", kind)
+	}
+	return p, nil
+}
+
+// endpointURL joins endpoint to the instance URL and adds the query. The
+// instance's extra params go first so the provider's parameters always win.
+func endpointURL(inst model.SearxInstance, endpoint string, params url.Values) (string, error) {
+	u, err := url.Parse(strings.TrimRight(inst.URL, "/"))
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	merged := u.Query()
+	extra, err := url.ParseQuery(inst.ExtraParams)
+	if err != nil {
+		return "", fmt.Errorf("extra params: %w", err)
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	u.RawQuery = merged.Encode()
+	return u.String(), nil
+}
+
+func newSearchRequest(ctx context.Context, target string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "discover/0.3")
+	return req, nil
+}
+
+// baseLanguage turns a SearXNG-style code such as en-US into en, for
+// backends that only take a language.
+func baseLanguage(lang string) string {
+	lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	return lang
+}
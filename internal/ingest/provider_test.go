@@ -0,0 +1,331 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"discover/internal/config"
+	"discover/internal/db"
+	"discover/internal/model"
+	"discover/internal/store"
+)
+
+// fixtureServer answers every request with a recorded response from testdata
+// after handing the request to check.
+func fixtureServer(t *testing.T, fixture string, check func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	body := readFixture(t, fixture)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// search sends one provider request to inst and parses the response.
+func search(t *testing.T, p SearchProvider, inst model.SearxInstance, q SearchQuery) []Entry {
+	t.Helper()
+	req, err := p.Request(context.Background(), inst, q)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := p.Parse(body)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return entries
+}
+
+func checkParams(t *testing.T, r *http.Request, path string, want map[string]string) {
+	t.Helper()
+	if r.URL.Path != path {
+		t.Errorf("path = %q, want %q", r.URL.Path, path)
+	}
+	got := r.URL.Query()
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("param %s = %q, want %q", k, got.Get(k), v)
+		}
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			t.Errorf("unexpected param %s=%q", k, got.Get(k))
+		}
+	}
+}
+
+func checkEntry(t *testing.T, got Entry, want Entry) {
+	t.Helper()
+	if got.URL != want.URL || got.Title != want.Title || got.Content != want.Content ||
+		got.Thumbnail != want.Thumbnail || got.Engines != want.Engines || !got.Published.Equal(want.Published) {
+		t.Errorf("entry = %+v\nwant    %+v", got, want)
+	}
+}
+
+func TestYaCyProvider(t *testing.T) {
+	srv := fixtureServer(t, "yacy_search.json", func(r *http.Request) {
+		checkParams(t, r, "/yacy/yacysearch.json", map[string]string{
+			"query":          "solar power",
+			"contentdom":     "text",
+			"maximumRecords": "10",
+			"startRecord":    "10",
+			"lr":             "lang_en",
+			"resource":       "global",
+		})
+	})
+	inst := model.SearxInstance{URL: srv.URL + "/yacy/", Provider: model.ProviderYaCy, ExtraParams: "resource=global&maximumRecords=99"}
+	entries := search(t, yacyProvider{}, inst, SearchQuery{Query: "solar power", Category: "news", TimeRange: "day", Language: "en-US", Page: 2, Count: 10})
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	checkEntry(t, entries[0], Entry{
+		URL:       "https://news.example.org/energy/community-solar",
+		Title:     "Community solar power scheme expands",
+		Content:   "The town's solar co-op adds 400 homes & a school.",
+		Engines:   1,
+		Published: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+	})
+	checkEntry(t, entries[1], Entry{
+		URL:     "https://blog.example.net/grid-storage",
+		Title:   "Grid storage and solar power",
+		Engines: 1,
+	})
+}
+
+func TestWhoogleProvider(t *testing.T) {
+	srv := fixtureServer(t, "whoogle_search.json", func(r *http.Request) {
+		checkParams(t, r, "/search", map[string]string{
+			"q":      "solar power",
+			"format": "json",
+			"start":  "20",
+			"tbm":    "nws",
+			"tbs":    "qdr:w",
+			"lr":     "lang_de",
+		})
+	})
+	inst := model.SearxInstance{URL: srv.URL, Provider: model.ProviderWhoogle}
+	entries := search(t, whoogleProvider{}, inst, SearchQuery{Query: "solar power", Category: "news", TimeRange: "week", Language: "de", Page: 3, Count: 30})
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	checkEntry(t, entries[0], Entry{
+		URL:     "https://news.example.org/energy/community-solar",
+		Title:   "Community solar power scheme expands - Example News",
+		Engines: 1,
+	})
+	checkEntry(t, entries[1], Entry{
+		URL:     "https://blog.example.net/grid-storage",
+		Title:   "Grid storage & solar power",
+		Engines: 1,
+	})
+}
+
+func TestBraveProvider(t *testing.T) {
+	checkHeaders := func(r *http.Request) {
+		if got := r.Header.Get("X-Subscription-Token"); got != "k1" {
+			t.Errorf("X-Subscription-Token = %q, want k1", got)
+		}
+		if got := r.Header.Get("Accept"); got != "application/json" {
+			t.Errorf("Accept = %q, want application/json", got)
+		}
+	}
+
+	t.Run("web", func(t *testing.T) {
+		srv := fixtureServer(t, "brave_web_search.json", func(r *http.Request) {
+			checkHeaders(r)
+			checkParams(t, r, "/res/v1/web/search", map[string]string{
+				"q":           "solar power",
+				"count":       "20",
+				"offset":      "9",
+				"freshness":   "pd",
+				"search_lang": "en",
+			})
+		})
+		inst := model.SearxInstance{URL: srv.URL + "/res/v1", Provider: model.ProviderBrave, APIKey: "k1"}
+		entries := search(t, braveProvider{}, inst, SearchQuery{Query: "solar power", TimeRange: "day", Language: "en-GB", Page: 12, Count: 50})
+		if len(entries) != 2 {
+			t.Fatalf("got %d entries, want 2", len(entries))
+		}
+		checkEntry(t, entries[0], Entry{
+			URL:       "https://news.example.org/energy/community-solar",
+			Title:     "Community solar power scheme expands",
+			Content:   "The town's solar co-op adds 400 homes.",
+			Thumbnail: "https://imgs.search.brave.com/thumb1.jpg",
+			Engines:   1,
+			Published: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+		})
+		checkEntry(t, entries[1], Entry{
+			URL:     "https://blog.example.net/grid-storage",
+			Title:   "Grid storage and solar power",
+			Content: "Batteries & panels.",
+			Engines: 1,
+		})
+	})
+
+	t.Run("news", func(t *testing.T) {
+		srv := fixtureServer(t, "brave_news_search.json", func(r *http.Request) {
+			checkHeaders(r)
+			checkParams(t, r, "/res/v1/news/search", map[string]string{
+				"q":      "solar power",
+				"count":  "10",
+				"offset": "0",
+			})
+		})
+		inst := model.SearxInstance{URL: srv.URL + "/res/v1/", Provider: model.ProviderBrave, APIKey: "k1"}
+		entries := search(t, braveProvider{}, inst, SearchQuery{Query: "solar power", Category: "news", Page: 1, Count: 10})
+		if len(entries) != 1 {
+			t.Fatalf("got %d entries, want 1", len(entries))
+		}
+		checkEntry(t, entries[0], Entry{
+			URL:       "https://news.example.org/energy/community-solar",
+			Title:     "Community solar power scheme expands",
+			Content:   "The town's solar co-op adds 400 homes.",
+			Thumbnail: "https://imgs.search.brave.com/thumb1.jpg",
+			Engines:   1,
+			Published: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+		})
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		inst := model.SearxInstance{URL: "https://api.search.brave.com/res/v1", Provider: model.ProviderBrave}
+		if _, err := (braveProvider{}).Request(context.Background(), inst, SearchQuery{Query: "x", Page: 1, Count: 10}); err == nil {
+			t.Fatal("expected an error without API key")
+		}
+	})
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// TestSearxngFormatFallback covers instances without "json" in
+// search.formats: the refused JSON request is retried as RSS and the
+// instance keeps using RSS afterwards.
+func TestSearxngFormatFallback(t *testing.T) {
+	rss := readFixture(t, "searxng_search.rss")
+	refusals := map[string]func(w http.ResponseWriter){
+		"403": func(w http.ResponseWriter) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		},
+		"html page": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write(readFixture(t, "searxng_search.html"))
+		},
+	}
+	for name, refuse := range refusals {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var formats []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				format := r.URL.Query().Get("format")
+				mu.Lock()
+				formats = append(formats, format)
+				mu.Unlock()
+				if r.URL.Path != "/search" || format != formatRSS {
+					refuse(w)
+					return
+				}
+				w.Header().Set("Content-Type", "application/rss+xml")
+				_, _ = w.Write(rss)
+			}))
+			defer srv.Close()
+			requested := func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				out := formats
+				formats = nil
+				return out
+			}
+
+			ctx := context.Background()
+			conn, err := db.Open(filepath.Join(t.TempDir(), "discover.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			st := store.New(conn)
+			if err := st.UpsertSearxInstance(ctx, model.SearxInstance{URL: srv.URL, Provider: model.ProviderSearxng, Enabled: true}); err != nil {
+				t.Fatal(err)
+			}
+			saved := func() model.SearxInstance {
+				list, err := st.ListSearxInstances(ctx)
+				if err != nil || len(list) != 1 {
+					t.Fatalf("ListSearxInstances = %v, %v", list, err)
+				}
+				return list[0]
+			}
+			s := New(config.Config{}, st)
+			h := harvest{categories: []string{""}, timeRanges: []string{""}, pages: 1}
+
+			entries, a := s.fetchHarvestFromInstance(ctx, saved(), "solar power", h)
+			if a.err != nil {
+				t.Fatalf("harvest error: %v", a.err)
+			}
+			if got := requested(); strings.Join(got, ",") != "json,rss" {
+				t.Errorf("requested formats %q, want json then rss", got)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("got %d entries, want 2", len(entries))
+			}
+			checkEntry(t, entries[0], Entry{
+				URL:       "https://news.example.org/energy/community-solar",
+				Title:     "Community solar power scheme expands",
+				Content:   "The town's solar co-op adds 400 homes & a school.",
+				Engines:   1,
+				Published: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC),
+			})
+			checkEntry(t, entries[1], Entry{
+				URL:     "https://blog.example.net/grid-storage",
+				Title:   "Grid storage and solar power",
+				Engines: 1,
+			})
+			if got := saved().ResponseFormat; got != formatRSS {
+				t.Fatalf("remembered format %q, want rss", got)
+			}
+
+			// The next run asks for RSS straight away.
+			if _, a := s.fetchHarvestFromInstance(ctx, saved(), "solar power", h); a.err != nil || a.requests != 1 {
+				t.Errorf("second harvest requests=%d err=%v", a.requests, a.err)
+			}
+			if got := requested(); strings.Join(got, ",") != "rss" {
+				t.Errorf("requested formats %q, want rss only", got)
+			}
+
+			// A probe of a saved instance finds and remembers RSS as well.
+			if err := st.SetSearxInstanceFormat(ctx, saved().ID, formatJSON); err != nil {
+				t.Fatal(err)
+			}
+			out := s.Probe(ctx, saved(), "solar power")
+			if out.Format != formatRSS || out.Results != 2 || !strings.Contains(out.Error, "now used for this instance") {
+				t.Errorf("probe = %+v", out)
+			}
+			if got := saved().ResponseFormat; got != formatRSS {
+				t.Errorf("format after probe %q, want rss", got)
+			}
+		})
+	}
+}
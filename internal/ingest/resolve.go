@@ -0,0 +1,165 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"discover/internal/store"
+	"discover/internal/urlcanon"
+)
+
+// maxRefreshHops bounds how many <meta refresh> interstitials (aggregator
+// redirect pages) are followed to reach the article.
+const maxRefreshHops = 2
+
+// resolvePassTimeout bounds one pass over the candidates, so slow sites
+// cannot hold up the rest of an ingest run. Articles not reached stay
+// unchecked for the next run.
+const resolvePassTimeout = 2 * time.Minute
+
+// resolveCanonical fetches the pages of unchecked unread articles, newest
+// first, and re-keys each under the URL its page calls canonical. Articles
+// that turn out to be the same page are merged. Every article is checked
+// once, whatever the outcome, unless the pass runs out of time first.
+func (s *Service) resolveCanonical(ctx context.Context, canon *urlcanon.Canonicalizer) {
+	if !s.cfg.ResolveCanonical {
+		return
+	}
+	articles, err := s.store.ListCanonicalCandidates(ctx, s.cfg.ResolveMaxPerRun)
+	if err != nil {
+		s.logf("ingest: canonical link candidates error: %v", err)
+		return
+	}
+	if len(articles) == 0 {
+		return
+	}
+	start := time.Now()
+	passCtx, cancel := context.WithTimeout(ctx, resolvePassTimeout)
+	defer cancel()
+	checked, rekeyed, merged, failed := 0, 0, 0, 0
+	for _, a := range articles {
+		if passCtx.Err() != nil {
+			s.logf("ingest: canonical links stopped after %d/%d article(s): %v", checked, len(articles), passCtx.Err())
+			break
+		}
+		target, err := s.canonicalTarget(passCtx, a.URL)
+		if passCtx.Err() != nil {
+			continue
+		}
+		checked++
+		if err != nil {
+			failed++
+			s.logf("ingest: canonical link url=%q error=%v", a.URL, err)
+		}
+		norm, domain, canonErr := canon.Canonical(target)
+		if err != nil || canonErr != nil || urlcanon.Hash(norm) == a.URLHash {
+			if err := s.store.MarkCanonicalChecked(ctx, a.ID); err != nil {
+				s.logf("ingest: canonical link mark error id=%d: %v", a.ID, err)
+			}
+			continue
+		}
+		keptID, wasMerged, err := s.store.RekeyArticle(ctx, a.ID, store.RekeyInput{
+			URL:           target,
+			NormalizedURL: norm,
+			URLHash:       urlcanon.Hash(norm),
+			SourceDomain:  domain,
+		})
+		switch {
+		case errors.Is(err, store.ErrCrossSiteCanonical):
+			failed++
+			s.logf("ingest: canonical link id=%d: %v", a.ID, err)
+			if err := s.store.MarkCanonicalChecked(ctx, a.ID); err != nil {
+				s.logf("ingest: canonical link mark error id=%d: %v", a.ID, err)
+			}
+		case err != nil:
+			failed++
+			s.logf("ingest: canonical link rekey error id=%d: %v", a.ID, err)
+		case wasMerged:
+			merged++
+			s.logf("ingest: canonical link id=%d is %s; merged with the article already there, kept id=%d", a.ID, norm, keptID)
+		default:
+			rekeyed++
+		}
+	}
+	s.logf("ingest: canonical links checked=%d rekeyed=%d merged=%d failed=%d took=%s", checked, rekeyed, merged, failed, time.Since(start).Round(time.Millisecond))
+}
+
+// canonicalTarget returns the URL an article page calls its own: the
+// canonical link, else og:url, else where redirects ended. A target on
+// another site than raw is only taken from AMP caches and aggregators;
+// otherwise raw itself is returned.
+func (s *Service) canonicalTarget(ctx context.Context, raw string) (string, error) {
+	origin := raw
+	timeout := time.Duration(s.cfg.ResolveTimeoutSec) * time.Second
+	for hop := 0; ; hop++ {
+		page, err := s.fetchArticlePage(ctx, raw, timeout, s.cfg.ResolveMaxBytes)
+		if err != nil {
+			return "", err
+		}
+		target := ""
+		for _, candidate := range []string{page.Canonical, page.Meta["og:url"]} {
+			if target = canonicalCandidate(page.FinalURL, candidate); target != "" {
+				break
+			}
+		}
+		if target == "" {
+			next := pageLink(page.FinalURL, page.Refresh)
+			if next != nil && hop < maxRefreshHops {
+				if err := s.pages.checkURL(next); err != nil {
+					return "", fmt.Errorf("refresh to %s: %w", next, err)
+				}
+				raw = next.String()
+				continue
+			}
+			target = page.FinalURL.String()
+		}
+		if !urlcanon.SameSite(origin, target) {
+			return origin, nil
+		}
+		return target, nil
+	}
+}
+
+// canonicalCandidate resolves ref against the page URL. Candidates that
+// are not http(s), that are on another site than the page (unless it is an
+// AMP cache or aggregator), or that point an article at its site's home
+// page (a common template mistake), are ignored.
+func canonicalCandidate(page *url.URL, ref string) string {
+	u := pageLink(page, ref)
+	if u == nil || !urlcanon.SameSite(page.String(), u.String()) {
+		return ""
+	}
+	return u.String()
+}
+
+// pageLink resolves ref against the page URL, or returns nil unless the
+// result is an http(s) URL that does not lead from an article to a home
+// page.
+func pageLink(page *url.URL, ref string) *url.URL {
+	u := absoluteURL(page, ref)
+	if u == nil {
+		return nil
+	}
+	if strings.Trim(u.Path, "/") == "" && u.RawQuery == "" && strings.Trim(page.Path, "/") != "" {
+		return nil
+	}
+	return u
+}
+
+// absoluteURL resolves ref against base, or returns nil unless the result
+// is an http(s) URL.
+func absoluteURL(base *url.URL, ref string) *url.URL {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	return u
+}
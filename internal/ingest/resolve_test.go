@@ -0,0 +1,148 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"discover/internal/config"
+)
+
+const testTimeout = 5 * time.Second
+
+// newTestService returns a Service that fetches pages from httptest servers.
+func newTestService() *Service {
+	guard := pageGuard{allowPrivate: true}
+	return &Service{
+		cfg:        config.Config{ResolveTimeoutSec: 5, ResolveMaxBytes: 1 << 20},
+		pages:      guard,
+		pageClient: guard.client(),
+	}
+}
+
+// pageServer serves the given <head> contents by path and counts requests.
+func pageServer(t *testing.T, heads map[string]string) (*httptest.Server, func(string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+		head, ok := heads[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<!doctype html><html><head>%s</head><body>text</body></html>", head)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+}
+
+func TestCanonicalTarget(t *testing.T) {
+	srv, hits := pageServer(t, map[string]string{
+		"/canonical":    `<link rel="canonical" href="/story?id=1"><meta property="og:url" content="/og">`,
+		"/og":           `<meta property="og:url" content="/story-og">`,
+		"/home":         `<link rel="canonical" href="/"><meta property="og:url" content="/">`,
+		"/other-site":   `<link rel="canonical" href="https://example.com/story">`,
+		"/plain":        `<title>no links</title>`,
+		"/refresh":      `<meta http-equiv="refresh" content="0; url=/refresh-1">`,
+		"/refresh-1":    `<meta http-equiv="refresh" content="0; url=/refresh-2">`,
+		"/refresh-2":    `<meta http-equiv="refresh" content="0; url=/refresh-3">`,
+		"/refresh-3":    `<link rel="canonical" href="/never">`,
+		"/refresh-bad":  `<meta http-equiv="refresh" content="0; url=ftp://example.com/a">`,
+		"/refresh-meta": `<meta http-equiv="refresh" content="0; url=http://169.254.169.254/latest/meta-data/">`,
+	})
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/canonical", srv.URL + "/story?id=1"},
+		{"/og", srv.URL + "/story-og"},
+		// A canonical home page is a template mistake, so the page stays.
+		{"/home", srv.URL + "/home"},
+		// Another site cannot claim the article.
+		{"/other-site", srv.URL + "/other-site"},
+		{"/plain", srv.URL + "/plain"},
+		// Refreshes are followed maxRefreshHops times, then the page
+		// reached is the target.
+		{"/refresh", srv.URL + "/refresh-2"},
+		{"/refresh-bad", srv.URL + "/refresh-bad"},
+	}
+	s := newTestService()
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := s.canonicalTarget(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("target = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if n := hits("/refresh-3"); n != 0 {
+		t.Errorf("refresh followed past %d hops", maxRefreshHops)
+	}
+
+	t.Run("refresh to a private address", func(t *testing.T) {
+		// Every host dials the test server, so only the URL checks guard
+		// the refresh.
+		s := newTestService()
+		s.pages = pageGuard{}
+		addr := srv.Listener.Addr().String()
+		s.pageClient.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}
+		_, err := s.canonicalTarget(context.Background(), "http://example.com/refresh-meta")
+		if err == nil || !strings.Contains(err.Error(), "non-public") {
+			t.Fatalf("err = %v, want a non-public address error", err)
+		}
+	})
+}
+
+func TestCanonicalCandidate(t *testing.T) {
+	page := mustParseURL(t, "https://www.example.com/news/story.html")
+	amp := mustParseURL(t, "https://www-example-com.cdn.ampproject.org/c/s/www.example.com/news/story.html")
+	tests := []struct {
+		page string
+		ref  string
+		want string
+	}{
+		{page.String(), "/news/story", "https://www.example.com/news/story"},
+		{page.String(), "https://example.com/news/story", "https://example.com/news/story"},
+		{page.String(), "https://news.example.com/story", "https://news.example.com/story"},
+		{page.String(), "https://www.example.com/", ""},
+		{page.String(), "https://example.org/news/story", ""},
+		{page.String(), "javascript:alert(1)", ""},
+		{page.String(), "", ""},
+		{amp.String(), "https://www.example.com/news/story.html", "https://www.example.com/news/story.html"},
+	}
+	for _, tt := range tests {
+		if got := canonicalCandidate(mustParseURL(t, tt.page), tt.ref); got != tt.want {
+			t.Errorf("canonicalCandidate(%s, %q) = %q, want %q", tt.page, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
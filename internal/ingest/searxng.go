@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"discover/internal/model"
+)
+
+// searxngProvider queries SearXNG's /search?format=json, which needs "json"
+// in the instance's search.formats. Many public instances only allow rss, so
+// a refused format is retried in the other one and the instance remembers
+// what worked.
+type searxngProvider struct{}
+
+const (
+	formatJSON = "json"
+	formatRSS  = "rss"
+)
+
+// errFormatRefused means SearXNG answered with its HTML page instead of the
+// requested format.
+var errFormatRefused = errors.New("instance answered HTML instead of JSON/RSS; the format is probably disabled")
+
+func (searxngProvider) Kind() string { return model.ProviderSearxng }
+
+func (searxngProvider) Format(inst model.SearxInstance) string { return searxFormat(inst) }
+
+func searxFormat(inst model.SearxInstance) string {
+	if inst.ResponseFormat == formatRSS {
+		return formatRSS
+	}
+	return formatJSON
+}
+
+func (searxngProvider) AltFormat(inst model.SearxInstance, err error) string {
+	var se *statusError
+	if !errors.Is(err, errFormatRefused) && !(errors.As(err, &se) && se.code == http.StatusForbidden) {
+		return ""
+	}
+	if searxFormat(inst) == formatRSS {
+		return formatJSON
+	}
+	return formatRSS
+}
+
+func (searxngProvider) Request(ctx context.Context, inst model.SearxInstance, q SearchQuery) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("q", q.Query)
+	if q.TimeRange != "" {
+		params.Set("time_range", q.TimeRange)
+	}
+	params.Set("format", searxFormat(inst))
+	params.Set("pageno", strconv.Itoa(q.Page))
+	params.Set("count", strconv.Itoa(q.Count))
+	if q.Category != "" {
+		params.Set("categories", q.Category)
+	}
+	if q.Engines != "" {
+		params.Set("engines", q.Engines)
+	}
+	if q.Language != "" {
+		params.Set("language", q.Language)
+	}
+	target, err := endpointURL(inst, "/search", params)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newSearchRequest(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if searxFormat(inst) == formatRSS {
+		req.Header.Set("Accept", "application/rss+xml, application/xml;q=0.9")
+	}
+	return req, nil
+}
+
+// Parse reads either output format: RSS items carry no engines or scores.
+func (searxngProvider) Parse(body []byte) ([]Entry, error) {
+	if trimmed := bytes.TrimSpace(body); bytes.HasPrefix(trimmed, []byte("<")) {
+		entries, err := parseFeed(trimmed, nil)
+		if err != nil {
+			return nil, errFormatRefused
+		}
+		for i := range entries {
+			entries[i].Engines = 1
+		}
+		return entries, nil
+	}
+	var parsed searxResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(parsed.Results))
+	for _, r := range parsed.Results {
+		out = append(out, r.toEntry())
+	}
+	return out, nil
+}
+
+type searxResponse struct {
+	Query   string       `json:"query"`
+	Results []searxEntry `json:"results"`
+}
+
+type searxEntry struct {
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	Thumbnail     string   `json:"thumbnail"`
+	ImgSrc        string   `json:"img_src"`
+	Engines       []string `json:"engines"`
+	ParsedURL     []string `json:"parsed_url"`
+	Score         float64  `json:"score"`
+	PublishedDate string   `json:"publishedDate"`
+	Pubdate       string   `json:"pubdate"`
+}
+
+func (e searxEntry) toEntry() Entry {
+	thumb := strings.TrimSpace(firstNonEmpty(e.Thumbnail, e.ImgSrc))
+	if thumb == "null" {
+		thumb = ""
+	}
+	return Entry{
+		URL:       e.URL,
+		Title:     e.Title,
+		Content:   e.Content,
+		Thumbnail: thumb,
+		Engines:   len(e.Engines),
+		Score:     e.Score,
+		Published: parsePublished(e.PublishedDate, e.Pubdate),
+	}
+}
@@ -0,0 +1,47 @@
+package ingest
+
+import (
+	"context"
+	"time"
+
+	"discover/internal/model"
+)
+
+// Entry is a single candidate article produced by a Source, already mapped
+// out of the upstream response shape.
+type Entry struct {
+	URL       string
+	Title     string
+	Content   string
+	Thumbnail string
+	Engines   int
+	Score     float64
+	Published time.Time
+	// Origin is the upstream that served the entry: the SearXNG instance
+	// or the feed host.
+	Origin string
+}
+
+// Source fetches candidate entries for one topic row. Sources are selected by
+// topic kind.
+type Source interface {
+	Kind() string
+	Fetch(ctx context.Context, topic model.Topic) ([]Entry, error)
+}
+
+type searxSource struct {
+	svc *Service
+}
+
+func (src *searxSource) Kind() string { return model.TopicKindSearch }
+
+func (src *searxSource) Fetch(ctx context.Context, topic model.Topic) ([]Entry, error) {
+	entries, instance, err := src.svc.fetchTopic(ctx, topic.Query, harvestFor(topic))
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Origin = instance
+	}
+	return entries, nil
+}
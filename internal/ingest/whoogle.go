@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"discover/internal/model"
+)
+
+// whoogleProvider queries Whoogle's JSON output (/search?format=json, in
+// releases that have it). Whoogle pages by 10 and forwards Google's news
+// (tbm=nws) and time (tbs=qdr:) filters.
+type whoogleProvider struct{}
+
+func (whoogleProvider) Kind() string { return model.ProviderWhoogle }
+
+var whoogleTimeRanges = map[string]string{"day": "qdr:d", "week": "qdr:w", "month": "qdr:m", "year": "qdr:y"}
+
+func (whoogleProvider) Request(ctx context.Context, inst model.SearxInstance, q SearchQuery) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("q", q.Query)
+	params.Set("format", "json")
+	if q.Page > 1 {
+		params.Set("start", strconv.Itoa((q.Page-1)*10))
+	}
+	if q.Category == "news" {
+		params.Set("tbm", "nws")
+	}
+	if tbs, ok := whoogleTimeRanges[q.TimeRange]; ok {
+		params.Set("tbs", tbs)
+	}
+	if lang := baseLanguage(q.Language); lang != "" {
+		params.Set("lr", "lang_"+lang)
+	}
+	target, err := endpointURL(inst, "/search", params)
+	if err != nil {
+		return nil, err
+	}
+	return newSearchRequest(ctx, target)
+}
+
+// whoogleResponse accepts both the href/text and url/title/content spellings
+// of a result.
+type whoogleResponse struct {
+	Results []struct {
+		Href    string `json:"href"`
+		URL     string `json:"url"`
+		Title   string `json:"title"`
+		Text    string `json:"text"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (whoogleProvider) Parse(body []byte) ([]Entry, error) {
+	var parsed whoogleResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(parsed.Results))
+	for _, r := range parsed.Results {
+		title, content := r.Title, r.Content
+		if title == "" {
+			title = r.Text
+		} else if content == "" {
+			content = r.Text
+		}
+		out = append(out, Entry{
+			URL:     firstNonEmpty(r.URL, r.Href),
+			Title:   htmlToText(title),
+			Content: htmlToText(content),
+			Engines: 1,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"discover/internal/model"
+)
+
+// yacyProvider queries a YaCy peer's /yacysearch.json. YaCy has no time
+// ranges or categories, so a harvest collapses to its pages.
+type yacyProvider struct{}
+
+func (yacyProvider) Kind() string { return model.ProviderYaCy }
+
+func (yacyProvider) Request(ctx context.Context, inst model.SearxInstance, q SearchQuery) (*http.Request, error) {
+	params := url.Values{}
+	params.Set("query", q.Query)
+	params.Set("contentdom", "text")
+	params.Set("maximumRecords", strconv.Itoa(q.Count))
+	params.Set("startRecord", strconv.Itoa((q.Page-1)*q.Count))
+	if lang := baseLanguage(q.Language); lang != "" {
+		params.Set("lr", "lang_"+lang)
+	}
+	target, err := endpointURL(inst, "/yacysearch.json", params)
+	if err != nil {
+		return nil, err
+	}
+	return newSearchRequest(ctx, target)
+}
+
+type yacyResponse struct {
+	Channels []struct {
+		Items []struct {
+			Title       string `json:"title"`
+			Link        string `json:"link"`
+			Description string `json:"description"`
+			PubDate     string `json:"pubDate"`
+			Image       string `json:"image"`
+		} `json:"items"`
+	} `json:"channels"`
+}
+
+func (yacyProvider) Parse(body []byte) ([]Entry, error) {
+	var parsed yacyResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, ch := range parsed.Channels {
+		for _, it := range ch.Items {
+			out = append(out, Entry{
+				URL:       it.Link,
+				Title:     htmlToText(it.Title),
+				Content:   htmlToText(it.Description),
+				Thumbnail: it.Image,
+				Engines:   1,
+				Published: parseFeedDate(it.PubDate),
+			})
+		}
+	}
+	return out, nil
+}
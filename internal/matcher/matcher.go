@@ -1,33 +1,150 @@
 package matcher
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-// MatchRule evaluates a rule pattern as a simple token matcher (no regex).
-// Pattern separators: spaces and '+' are both treated as token separators.
+// Rule is a compiled rule expression. See Compile for the grammar.
+type Rule struct {
+	root node
+}
+
+// Doc holds the article fields a rule is matched against, lowercased and with
+// whitespace collapsed so phrases match across line breaks.
+type Doc struct {
+	Title   string
+	Content string
+	Domain  string
+	URL     string
+}
+
+func NewDoc(title, content, domain, articleURL string) Doc {
+	return Doc{
+		Title:   normalizeText(title),
+		Content: normalizeText(content),
+		Domain:  normalizeText(domain),
+		URL:     normalizeText(articleURL),
+	}
+}
+
+func (r *Rule) Match(d Doc) bool {
+	if r == nil || r.root == nil {
+		return false
+	}
+	return r.root.match(d)
+}
+
+// MatchRule compiles and evaluates a rule pattern in one step. Invalid
+// patterns never match.
 func MatchRule(pattern, title, content, domain, articleURL string) bool {
-	tokens := tokenizePattern(pattern)
-	if len(tokens) == 0 {
+	r, err := Compile(pattern)
+	if err != nil {
 		return false
 	}
-	haystack := strings.ToLower(strings.Join([]string{
-		title,
-		content,
-		domain,
-		articleURL,
-	}, " "))
-	for _, tok := range tokens {
-		if !strings.Contains(haystack, tok) {
+	return r.Match(NewDoc(title, content, domain, articleURL))
+}
+
+type node interface {
+	match(d Doc) bool
+}
+
+type andNode []node
+
+func (n andNode) match(d Doc) bool {
+	for _, c := range n {
+		if !c.match(d) {
 			return false
 		}
 	}
 	return true
 }
 
-func tokenizePattern(pattern string) []string {
-	pattern = strings.ToLower(strings.TrimSpace(pattern))
-	if pattern == "" {
-		return nil
+type orNode []node
+
+func (n orNode) match(d Doc) bool {
+	for _, c := range n {
+		if c.match(d) {
+			return true
+		}
 	}
-	pattern = strings.ReplaceAll(pattern, "+", " ")
-	return strings.Fields(pattern)
+	return false
+}
+
+type notNode struct {
+	n node
+}
+
+func (n notNode) match(d Doc) bool {
+	return !n.n.match(d)
+}
+
+type termNode struct {
+	scope  string
+	text   string
+	prefix bool
+}
+
+func (n termNode) match(d Doc) bool {
+	switch n.scope {
+	case ScopeTitle:
+		return containsWord(d.Title, n.text, n.prefix)
+	case ScopeContent:
+		return containsWord(d.Content, n.text, n.prefix)
+	case ScopeDomain:
+		return containsWord(d.Domain, n.text, n.prefix)
+	case ScopeURL:
+		return containsWord(d.URL, n.text, n.prefix)
+	default:
+		return containsWord(d.Title, n.text, n.prefix) ||
+			containsWord(d.Content, n.text, n.prefix) ||
+			containsWord(d.Domain, n.text, n.prefix) ||
+			containsWord(d.URL, n.text, n.prefix)
+	}
+}
+
+// containsWord reports whether term occurs in text on word boundaries. A
+// boundary is only required where the term itself starts or ends with a
+// letter or digit, so "theinformation.com" still matches inside
+// "www.theinformation.com". With prefix set the end boundary is not checked.
+func containsWord(text, term string, prefix bool) bool {
+	if term == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(term)
+	last, _ := utf8.DecodeLastRuneInString(term)
+	needStart := isWordRune(first)
+	needEnd := isWordRune(last) && !prefix
+	for from := 0; from <= len(text)-len(term); {
+		i := strings.Index(text[from:], term)
+		if i < 0 {
+			return false
+		}
+		start := from + i
+		end := start + len(term)
+		ok := true
+		if needStart && start > 0 {
+			r, _ := utf8.DecodeLastRuneInString(text[:start])
+			ok = !isWordRune(r)
+		}
+		if ok && needEnd && end < len(text) {
+			r, _ := utf8.DecodeRuneInString(text[end:])
+			ok = !isWordRune(r)
+		}
+		if ok {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		from = start + size
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
 }
@@ -0,0 +1,303 @@
+package matcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	ScopeTitle   = "title"
+	ScopeContent = "content"
+	ScopeDomain  = "domain"
+	ScopeURL     = "url"
+)
+
+var knownScopes = map[string]bool{ScopeTitle: true, ScopeContent: true, ScopeDomain: true, ScopeURL: true}
+
+// Compile parses a rule pattern.
+//
+//	apple pie             both words, anywhere (implicit AND)
+//	get+off               same as "get off"
+//	"apple pie"           exact phrase
+//	apple OR pear         either word
+//	-recipe               must not contain the word
+//	(apple OR pear) -pie  grouping
+//	title:apple           limit to one field: title, content, domain, url
+//	domain:(a.com OR b.com), title:"apple pie"
+//	shoot*                prefix match (shoot, shooter, shooting)
+//
+// Words match on word boundaries, so "apple" does not match "pineapple".
+// A pattern must contain at least one term that is not negated.
+func Compile(pattern string) (*Rule, error) {
+	toks, err := lex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return nil, errors.New("empty pattern")
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %s", p.toks[p.pos])
+	}
+	if !hasPositive(root) {
+		return nil, errors.New("pattern needs at least one term that is not negated")
+	}
+	return &Rule{root: root}, nil
+}
+
+// Validate reports whether pattern is a valid rule expression.
+func Validate(pattern string) error {
+	_, err := Compile(pattern)
+	return err
+}
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokScope
+	tokLParen
+	tokRParen
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind   tokenKind
+	scope  string
+	text   string
+	phrase bool
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return `"-"`
+	case tokScope:
+		return fmt.Sprintf("%q", t.scope+":")
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func lex(pattern string) ([]token, error) {
+	rs := []rune(strings.TrimSpace(pattern))
+	var out []token
+	i := 0
+	for i < len(rs) {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r) || r == '+':
+			i++
+		case r == '(':
+			out = append(out, token{kind: tokLParen})
+			i++
+		case r == ')':
+			out = append(out, token{kind: tokRParen})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, errors.New("unterminated quote")
+			}
+			text := normalizeText(string(rs[i+1 : end]))
+			if text == "" {
+				return nil, errors.New("empty phrase")
+			}
+			out = append(out, token{kind: tokTerm, text: text, phrase: true})
+			i = end + 1
+		case r == '-' && (i+1 >= len(rs) || unicode.IsSpace(rs[i+1])):
+			// A lone dash is punctuation ("Foo - Bar"), not negation.
+			i++
+		case r == '-':
+			out = append(out, token{kind: tokNot})
+			i++
+		default:
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '+' && rs[i] != '(' && rs[i] != ')' && rs[i] != '"' {
+				i++
+			}
+			word := string(rs[start:i])
+			if word == "OR" || word == "|" {
+				out = append(out, token{kind: tokOr})
+				continue
+			}
+			if word == "AND" {
+				continue
+			}
+			if scope, rest, ok := strings.Cut(word, ":"); ok && knownScopes[strings.ToLower(scope)] {
+				scope = strings.ToLower(scope)
+				if rest == "" && i < len(rs) && (rs[i] == '(' || rs[i] == '"') {
+					out = append(out, token{kind: tokScope, scope: scope})
+					continue
+				}
+				if rest != "" {
+					out = append(out, token{kind: tokTerm, scope: scope, text: strings.ToLower(rest)})
+					continue
+				}
+			}
+			out = append(out, token{kind: tokTerm, text: strings.ToLower(word)})
+		}
+	}
+	return out, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.toks) {
+		return token{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *parser) parseOr(scope string) (node, error) {
+	first, err := p.parseAnd(scope)
+	if err != nil {
+		return nil, err
+	}
+	alts := orNode{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseAnd(scope)
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, next)
+	}
+	if len(alts) == 1 {
+		return first, nil
+	}
+	return alts, nil
+}
+
+func (p *parser) parseAnd(scope string) (node, error) {
+	var all andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		n, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, n)
+	}
+	switch len(all) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, fmt.Errorf("expected a term before %s", t)
+		}
+		return nil, errors.New("expected a term at end of pattern")
+	case 1:
+		return all[0], nil
+	default:
+		return all, nil
+	}
+}
+
+func (p *parser) parseUnary(scope string) (node, error) {
+	t, _ := p.peek()
+	if t.kind == tokNot {
+		p.pos++
+		if _, ok := p.peek(); !ok {
+			return nil, errors.New(`"-" must be followed by a term`)
+		}
+		n, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n: n}, nil
+	}
+	return p.parsePrimary(scope)
+}
+
+func (p *parser) parsePrimary(scope string) (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of pattern")
+	}
+	switch t.kind {
+	case tokScope:
+		p.pos++
+		return p.parsePrimary(t.scope)
+	case tokLParen:
+		p.pos++
+		n, err := p.parseOr(scope)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := p.peek(); !ok || t.kind != tokRParen {
+			return nil, errors.New(`missing ")"`)
+		}
+		p.pos++
+		return n, nil
+	case tokTerm:
+		p.pos++
+		term := termNode{scope: scope, text: t.text}
+		if t.scope != "" {
+			term.scope = t.scope
+		}
+		if !t.phrase && strings.HasSuffix(term.text, "*") {
+			term.text = strings.TrimRight(term.text, "*")
+			term.prefix = true
+		}
+		if term.text == "" {
+			return nil, errors.New(`"*" needs a word before it`)
+		}
+		return term, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+}
+
+// hasPositive reports whether every way of satisfying n requires at least one
+// term to be present, so a rule cannot match articles by absence alone.
+func hasPositive(n node) bool {
+	switch v := n.(type) {
+	case termNode:
+		return true
+	case notNode:
+		return false
+	case andNode:
+		for _, c := range v {
+			if hasPositive(c) {
+				return true
+			}
+		}
+		return false
+	case orNode:
+		for _, c := range v {
+			if !hasPositive(c) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -12,35 +12,266 @@ const (
 	StatusRead   ArticleStatus = "read"
 )
 
+const (
+	TopicKindSearch = "search"
+	TopicKindFeed   = "feed"
+)
+
+// Search backends an instance can speak.
+const (
+	ProviderSearxng = "searxng"
+	ProviderYaCy    = "yacy"
+	ProviderWhoogle = "whoogle"
+	ProviderBrave   = "brave"
+)
+
+const (
+	RuleKindPenalty = "penalty"
+	RuleKindBoost   = "boost"
+)
+
+const (
+	ScoreBase            = "base"
+	ScoreTopicWeight     = "topic_weight"
+	ScoreEngines         = "engines"
+	ScoreSearx           = "searx_score"
+	ScoreTermBoost       = "term_boost"
+	ScoreRulePenalty     = "rule_penalty"
+	ScoreRuleBoost       = "rule_boost"
+	ScoreFloor           = "floor"
+	ScoreRuleRetroactive = "rule_retroactive"
+	ScoreFeedback        = "feedback"
+)
+
+// Sources recorded with status changes. Changes made by the system (auto-hide,
+// dedupe, undo) are logged but cannot be undone.
+const (
+	StatusSourceUp       = "up"
+	StatusSourceHide     = "hide"
+	StatusSourceDontShow = "dontshow"
+	StatusSourceClick    = "click"
+	StatusSourceSeen     = "seen"
+	StatusSourceFever    = "fever"
+	StatusSourceAutoHide = "auto_hide"
+	StatusSourceDedupe   = "dedupe"
+	StatusSourceUndo     = "undo"
+)
+
+// HalfLifeInherit marks a topic that uses the global recency half-life
+// (recency_half_life_hours). A Topic.HalfLifeHours of 0 turns decay off.
+const HalfLifeInherit = -1
+
 type Topic struct {
-	ID      int64   `json:"id"`
-	Query   string  `json:"query"`
-	Weight  float64 `json:"weight"`
-	Enabled bool    `json:"enabled"`
+	ID            int64   `json:"id"`
+	Kind          string  `json:"kind"`
+	Query         string  `json:"query"`
+	Weight        float64 `json:"weight"`
+	Enabled       bool    `json:"enabled"`
+	HalfLifeHours float64 `json:"half_life_hours"`
+	// SearXNG harvest parameters for search topics. Empty values use the
+	// defaults: categories news and general, time ranges day and week, 2 pages.
+	Categories []string `json:"categories"`
+	Engines    []string `json:"engines"`
+	Language   string   `json:"language"`
+	TimeRanges []string `json:"time_ranges"`
+	Pages      int      `json:"pages"`
+	// MinIntervalMinutes skips the topic on runs that start sooner than this
+	// after its last successful fetch; 0 fetches it on every run.
+	MinIntervalMinutes int       `json:"min_interval_minutes"`
+	LastFetchedAt      time.Time `json:"last_fetched_at"`
 }
 
 type NegativeRule struct {
 	ID           int64   `json:"id"`
+	Kind         string  `json:"kind"`
 	Pattern      string  `json:"pattern"`
 	Penalty      float64 `json:"penalty"`
 	Enabled      bool    `json:"enabled"`
 	AppliedCount int64   `json:"applied_count"`
+	// Error is set on listing when a stored pattern no longer compiles;
+	// ingestion skips such rules.
+	Error string `json:"error,omitempty"`
 }
 
 type Article struct {
-	ID            int64         `json:"id"`
-	URL           string        `json:"url"`
-	NormalizedURL string        `json:"normalized_url"`
-	URLHash       string        `json:"url_hash"`
-	Title         string        `json:"title"`
-	Content       string        `json:"content"`
-	ThumbnailURL  string        `json:"thumbnail_url"`
-	SourceDomain  string        `json:"source_domain"`
-	PublishedAt   time.Time     `json:"published_at"`
-	IngestedAt    time.Time     `json:"ingested_at"`
-	Status        ArticleStatus `json:"status"`
-	Score         float64       `json:"score"`
-	HitCount      int           `json:"hit_count"`
-	EngineCount   int           `json:"engine_count"`
-	SearxScore    float64       `json:"searx_score"`
+	ID              int64         `json:"id"`
+	URL             string        `json:"url"`
+	NormalizedURL   string        `json:"normalized_url"`
+	URLHash         string        `json:"url_hash"`
+	Title           string        `json:"title"`
+	Content         string        `json:"content"`
+	ThumbnailURL    string        `json:"thumbnail_url"`
+	SourceDomain    string        `json:"source_domain"`
+	SiteName        string        `json:"site_name"`
+	PublishedAt     time.Time     `json:"published_at"`
+	IngestedAt      time.Time     `json:"ingested_at"`
+	Status          ArticleStatus `json:"status"`
+	StatusChangedAt time.Time     `json:"status_changed_at"`
+	Score           float64       `json:"score"`
+	DecayedScore    float64       `json:"decayed_score"`
+	HitCount        int           `json:"hit_count"`
+	EngineCount     int           `json:"engine_count"`
+	SearxScore      float64       `json:"searx_score"`
+	StoryID         int64         `json:"story_id"`
+}
+
+const (
+	RunRunning     = "running"
+	RunDone        = "done"
+	RunFailed      = "failed"
+	RunCancelled   = "cancelled"
+	RunInterrupted = "interrupted"
+)
+
+type IngestRun struct {
+	ID              int64     `json:"id"`
+	JobID           int64     `json:"job_id"`
+	Source          string    `json:"source"`
+	Status          string    `json:"status"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	Error           string    `json:"error"`
+	Topics          int       `json:"topics"`
+	FailedTopics    int       `json:"failed_topics"`
+	FetchedEntries  int       `json:"fetched_entries"`
+	NewArticles     int       `json:"new_articles"`
+	UpdatedArticles int       `json:"updated_articles"`
+}
+
+type IngestRunTopic struct {
+	ID              int64     `json:"id"`
+	RunID           int64     `json:"run_id"`
+	TopicID         int64     `json:"topic_id"`
+	Kind            string    `json:"kind"`
+	Query           string    `json:"query"`
+	Instance        string    `json:"instance"`
+	Results         int       `json:"results"`
+	NewArticles     int       `json:"new_articles"`
+	UpdatedArticles int       `json:"updated_articles"`
+	Error           string    `json:"error"`
+	DurationMS      int64     `json:"duration_ms"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// SearxInstance is a search backend topics are fetched from: a SearXNG
+// instance unless Provider says otherwise. Higher priority instances are tried
+// first; DelaySeconds spaces out requests to the instance and ExtraParams (a
+// URL query string) is added to every search. APIKey is only sent by
+// providers that need one and is never returned by the admin API.
+// ResponseFormat is the SearXNG output format last seen working: json, rss,
+// or empty until detected.
+type SearxInstance struct {
+	ID             int64     `json:"id"`
+	URL            string    `json:"url"`
+	Provider       string    `json:"provider"`
+	APIKey         string    `json:"api_key,omitempty"`
+	HasAPIKey      bool      `json:"has_api_key"`
+	Enabled        bool      `json:"enabled"`
+	Priority       int       `json:"priority"`
+	DelaySeconds   int       `json:"delay_seconds"`
+	ExtraParams    string    `json:"extra_params"`
+	ResponseFormat string    `json:"response_format"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// URLRule adjusts URL canonicalization for a domain and its subdomains.
+// KeepParams and StripParams are comma-separated query parameter names; a
+// trailing * matches a prefix. A non-empty KeepParams keeps only those
+// parameters, StripParams drops parameters in addition to the built-in
+// tracking list.
+type URLRule struct {
+	ID          int64     `json:"id"`
+	Domain      string    `json:"domain"`
+	KeepParams  string    `json:"keep_params"`
+	StripParams string    `json:"strip_params"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// InstanceProbe is the outcome of a test search against an instance.
+type InstanceProbe struct {
+	URL         string `json:"url"`
+	Reachable   bool   `json:"reachable"`
+	StatusCode  int    `json:"status_code"`
+	JSONEnabled bool   `json:"json_enabled"`
+	Format      string `json:"format"`
+	Results     int    `json:"results"`
+	LatencyMS   int64  `json:"latency_ms"`
+	Error       string `json:"error"`
+}
+
+// InstanceHealth tracks how a SearXNG instance has served topic fetches. One
+// attempt is one topic harvest against the instance; an empty attempt
+// succeeded without results.
+type InstanceHealth struct {
+	URL                 string    `json:"url"`
+	Attempts            int64     `json:"attempts"`
+	Successes           int64     `json:"successes"`
+	Failures            int64     `json:"failures"`
+	EmptyResults        int64     `json:"empty_results"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	AvgLatencyMS        float64   `json:"avg_latency_ms"`
+	LastError           string    `json:"last_error"`
+	LastErrorAt         time.Time `json:"last_error_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	BlockedUntil        time.Time `json:"blocked_until"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// TopicRunStats summarizes recorded runs of one topic. EmptyStreak counts the
+// runs since the topic last returned anything.
+type TopicRunStats struct {
+	TopicID        int64     `json:"topic_id"`
+	Kind           string    `json:"kind"`
+	Query          string    `json:"query"`
+	Enabled        bool      `json:"enabled"`
+	Runs           int       `json:"runs"`
+	LastRunAt      time.Time `json:"last_run_at"`
+	LastResults    int       `json:"last_results"`
+	LastError      string    `json:"last_error"`
+	LastNonEmptyAt time.Time `json:"last_nonempty_at"`
+	EmptyStreak    int       `json:"empty_streak"`
+}
+
+type Story struct {
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	ArticleCount int       `json:"article_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type FeedToken struct {
+	ID         int64     `json:"id"`
+	Label      string    `json:"label"`
+	Prefix     string    `json:"prefix"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type ScoreEvent struct {
+	ID        int64     `json:"id"`
+	ArticleID int64     `json:"article_id"`
+	Component string    `json:"component"`
+	RefID     int64     `json:"ref_id"`
+	Detail    string    `json:"detail"`
+	Delta     float64   `json:"delta"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScoreComponent struct {
+	Component string  `json:"component"`
+	RefID     int64   `json:"ref_id"`
+	Detail    string  `json:"detail"`
+	Delta     float64 `json:"delta"`
+	Count     int     `json:"count"`
+}
+
+type ScoreExplanation struct {
+	Article     Article          `json:"article"`
+	Components  []ScoreComponent `json:"components"`
+	Events      []ScoreEvent     `json:"events"`
+	LedgerTotal float64          `json:"ledger_total"`
+	Unexplained float64          `json:"unexplained"`
 }
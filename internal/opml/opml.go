@@ -0,0 +1,194 @@
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline carries discover-specific data on custom attributes so that other
+// readers can still import the feed outlines and ignore the rest. List
+// attributes (categories, engines, time ranges) are comma-separated.
+type Outline struct {
+	Text        string    `xml:"text,attr"`
+	Title       string    `xml:"title,attr,omitempty"`
+	Type        string    `xml:"type,attr,omitempty"`
+	XMLURL      string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL     string    `xml:"htmlUrl,attr,omitempty"`
+	Kind        string    `xml:"discoverKind,attr,omitempty"`
+	Query       string    `xml:"discoverQuery,attr,omitempty"`
+	Weight      string    `xml:"discoverWeight,attr,omitempty"`
+	Enabled     string    `xml:"discoverEnabled,attr,omitempty"`
+	HalfLife    string    `xml:"discoverHalfLifeHours,attr,omitempty"`
+	Categories  string    `xml:"discoverCategories,attr,omitempty"`
+	Engines     string    `xml:"discoverEngines,attr,omitempty"`
+	Language    string    `xml:"discoverLanguage,attr,omitempty"`
+	TimeRanges  string    `xml:"discoverTimeRanges,attr,omitempty"`
+	Pages       string    `xml:"discoverPages,attr,omitempty"`
+	MinInterval string    `xml:"discoverMinIntervalMinutes,attr,omitempty"`
+	Outlines    []Outline `xml:"outline"`
+}
+
+func Export(topics []model.Topic, now time.Time) ([]byte, error) {
+	search := Outline{Text: "Discover search topics"}
+	feeds := Outline{Text: "Discover feeds"}
+	for _, t := range topics {
+		o := Outline{
+			Kind:    t.Kind,
+			Weight:  strconv.FormatFloat(t.Weight, 'f', -1, 64),
+			Enabled: strconv.FormatBool(t.Enabled),
+		}
+		if t.HalfLifeHours >= 0 {
+			o.HalfLife = strconv.FormatFloat(t.HalfLifeHours, 'f', -1, 64)
+		}
+		if t.MinIntervalMinutes > 0 {
+			o.MinInterval = strconv.Itoa(t.MinIntervalMinutes)
+		}
+		if t.Kind == model.TopicKindFeed {
+			o.Text = feedLabel(t.Query)
+			o.Type = "rss"
+			o.XMLURL = t.Query
+			feeds.Outlines = append(feeds.Outlines, o)
+			continue
+		}
+		o.Kind = model.TopicKindSearch
+		o.Text = t.Query
+		o.Query = t.Query
+		o.Categories = strings.Join(t.Categories, ",")
+		o.Engines = strings.Join(t.Engines, ",")
+		o.Language = t.Language
+		o.TimeRanges = strings.Join(t.TimeRanges, ",")
+		if t.Pages > 0 {
+			o.Pages = strconv.Itoa(t.Pages)
+		}
+		search.Outlines = append(search.Outlines, o)
+	}
+	doc := Document{
+		Version: "2.0",
+		Head:    Head{Title: "Discover topics", DateCreated: now.UTC().Format(time.RFC1123Z)},
+	}
+	for _, group := range []Outline{search, feeds} {
+		if len(group.Outlines) > 0 {
+			doc.Body.Outlines = append(doc.Body.Outlines, group)
+		}
+	}
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(b, '\n')...), nil
+}
+
+// Import flattens nested outlines into topics. Outlines with an xmlUrl become
+// feed topics; outlines tagged with discoverKind="search" become search
+// topics. Folders and unrecognized leaves are skipped.
+func Import(r io.Reader) ([]model.Topic, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(doc.XMLName.Local, "opml") {
+		return nil, errors.New("not an OPML document")
+	}
+	var out []model.Topic
+	var walk func([]Outline)
+	walk = func(items []Outline) {
+		for _, o := range items {
+			if t, ok := o.topic(); ok {
+				out = append(out, t)
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+	return out, nil
+}
+
+func (o Outline) topic() (model.Topic, bool) {
+	t := model.Topic{Weight: 1, Enabled: true, HalfLifeHours: model.HalfLifeInherit}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(o.Weight), 64); err == nil && v != 0 {
+		t.Weight = v
+	}
+	if v, err := strconv.ParseBool(strings.TrimSpace(o.Enabled)); err == nil {
+		t.Enabled = v
+	}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(o.HalfLife), 64); err == nil && v >= 0 && v <= 8760 {
+		t.HalfLifeHours = v
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(o.MinInterval)); err == nil && v > 0 {
+		t.MinIntervalMinutes = v
+	}
+	kind := strings.ToLower(strings.TrimSpace(o.Kind))
+	switch {
+	case strings.TrimSpace(o.XMLURL) != "" && kind != model.TopicKindSearch:
+		t.Kind = model.TopicKindFeed
+		t.Query = strings.TrimSpace(o.XMLURL)
+	case kind == model.TopicKindSearch:
+		t.Kind = model.TopicKindSearch
+		t.Query = strings.TrimSpace(firstNonEmpty(o.Query, o.Text, o.Title))
+		t.Categories = splitList(o.Categories)
+		t.Engines = splitList(o.Engines)
+		t.Language = strings.TrimSpace(o.Language)
+		t.TimeRanges = splitList(o.TimeRanges)
+		if v, err := strconv.Atoi(strings.TrimSpace(o.Pages)); err == nil && v > 0 {
+			t.Pages = v
+		}
+	default:
+		return model.Topic{}, false
+	}
+	if t.Query == "" {
+		return model.Topic{}, false
+	}
+	return t, true
+}
+
+func feedLabel(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Host == "" {
+		return feedURL
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
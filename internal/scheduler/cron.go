@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a standard 5-field cron expression: minute, hour, day of month,
+// month and day of week. Each field is a bit set of the values it matches.
+type cronSpec struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	loc                           *time.Location
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronDayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// cronSearchYears bounds the search for the next match, so expressions that
+// can never fire (e.g. "0 0 31 2 *") are rejected instead of looping forever.
+const cronSearchYears = 5
+
+func parseCron(expr string, loc *time.Location) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if m, ok := cronMacros[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(m)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q: expected 5 fields (minute hour day-of-month month day-of-week)", expr)
+	}
+	c := &cronSpec{expr: expr, loc: loc}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday too
+	}
+	c.domAny = strings.HasPrefix(fields[2], "*")
+	c.dowAny = strings.HasPrefix(fields[4], "*")
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%q never matches", expr)
+	}
+	return c, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges (a-b), "*"
+// and steps (*/n, a-b/n, a/n). names, if set, are accepted in place of numbers
+// starting at index 0.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepRaw, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepRaw)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loRaw, hiRaw, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = cronValue(loRaw, min, max, names); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = cronValue(hiRaw, min, max, names); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func cronValue(raw string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(raw, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("value %q out of range %d-%d", raw, min, max)
+	}
+	return v, nil
+}
+
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	// As in cron(8): when both day fields are restricted, either may match.
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next returns the first matching minute after t. The search runs on
+// wall-clock time in UTC, which has no DST jumps, and maps matches back to
+// loc: wall-clock times skipped by a DST change never match and repeated ones
+// match once.
+func (c *cronSpec) Next(t time.Time) time.Time {
+	local := t.In(c.loc)
+	n := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute()+1, 0, 0, time.UTC)
+	limit := n.Year() + cronSearchYears
+	for n.Year() <= limit {
+		switch {
+		case c.month&(1<<uint(n.Month())) == 0:
+			n = time.Date(n.Year(), n.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(n):
+			n = time.Date(n.Year(), n.Month(), n.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(n.Hour())) == 0:
+			n = n.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(n.Minute())) == 0:
+			// Jump straight to the next matching minute in this hour, if any.
+			if rest := c.minute >> uint(n.Minute()); rest != 0 {
+				n = n.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+			} else {
+				n = n.Truncate(time.Hour).Add(time.Hour)
+			}
+		default:
+			if at, ok := wallClock(n, c.loc); ok && at.After(t) {
+				return at
+			}
+			n = n.Add(time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+// wallClock returns the time in loc showing the same date and clock as the
+// UTC time n; ok is false when that clock time does not exist in loc.
+func wallClock(n time.Time, loc *time.Location) (time.Time, bool) {
+	at := time.Date(n.Year(), n.Month(), n.Day(), n.Hour(), n.Minute(), 0, 0, loc)
+	return at, at.Hour() == n.Hour() && at.Minute() == n.Minute()
+}
+
+func (c *cronSpec) String() string { return "cron " + c.expr + " (" + c.loc.String() + ")" }
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+
+	maxJobs     = 20
+	maxJobLines = 5000
+)
+
+// Job is one ingestion run, manual or scheduled, with the progress lines it
+// logged.
+type Job struct {
+	ID         int64     `json:"id"`
+	Source     string    `json:"source"`
+	State      string    `json:"state"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error"`
+	LineCount  int       `json:"line_count"`
+
+	lines   []string
+	changed chan struct{}
+}
+
+type jobCtxKey struct{}
+
+type jobRef struct {
+	id     int64
+	source string
+}
+
+// JobFromContext reports the job id and source ("manual" or "scheduled") of
+// the run ctx belongs to.
+func JobFromContext(ctx context.Context) (int64, string, bool) {
+	ref, ok := ctx.Value(jobCtxKey{}).(jobRef)
+	return ref.id, ref.source, ok
+}
+
+// touch wakes everyone waiting on the job. Callers hold the scheduler lock.
+func (j *Job) touch() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// newJob registers a running job. Callers hold the scheduler lock.
+func (s *Scheduler) newJob(source string, startedAt time.Time) *Job {
+	s.nextJobID++
+	j := &Job{ID: s.nextJobID, Source: source, State: JobRunning, StartedAt: startedAt, changed: make(chan struct{})}
+	s.jobs = append(s.jobs, j)
+	if len(s.jobs) > maxJobs {
+		s.jobs = s.jobs[len(s.jobs)-maxJobs:]
+	}
+	s.current = j
+	return j
+}
+
+// Progress appends a line to the running job's log. Lines logged while no job
+// runs are dropped.
+func (s *Scheduler) Progress(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		s.current.addLine(line)
+	}
+}
+
+// addLine appends to the job's log. Callers hold the scheduler lock.
+func (j *Job) addLine(line string) {
+	if len(j.lines) >= maxJobLines {
+		j.lines = append(j.lines[:0], j.lines[len(j.lines)-maxJobLines/2:]...)
+	}
+	j.lines = append(j.lines, line)
+	j.LineCount++
+	j.touch()
+}
+
+// Jobs lists recent jobs, newest first.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Job, 0, len(s.jobs))
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		out = append(out, *s.jobs[i])
+	}
+	return out
+}
+
+// Job returns a copy of the job with its log; id 0 means the latest job.
+func (s *Scheduler) Job(id int64) (Job, []string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.findJob(id)
+	if j == nil {
+		return Job{}, nil, false
+	}
+	return *j, append([]string(nil), j.lines...), true
+}
+
+// WatchJob returns the job, the log lines after the first `from` lines the
+// caller already has, and a channel closed on the next change. from counts
+// every line ever logged, so it stays valid when old lines are trimmed.
+func (s *Scheduler) WatchJob(id int64, from int) (Job, []string, <-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.findJob(id)
+	if j == nil {
+		return Job{}, nil, nil, false
+	}
+	trimmed := j.LineCount - len(j.lines)
+	start := from - trimmed
+	if start < 0 {
+		start = 0
+	}
+	var lines []string
+	if start < len(j.lines) {
+		lines = append(lines, j.lines[start:]...)
+	}
+	return *j, lines, j.changed, true
+}
+
+func (s *Scheduler) findJob(id int64) *Job {
+	if id == 0 {
+		if len(s.jobs) == 0 {
+			return nil
+		}
+		return s.jobs[len(s.jobs)-1]
+	}
+	for _, j := range s.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
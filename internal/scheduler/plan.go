@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan decides when scheduled runs happen.
+type Plan interface {
+	// Next returns the first run time after t, or the zero time if there is
+	// none.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// PlanConfig holds the schedule settings from the config file. The first one
+// set wins: Cron, then Times, then IntervalMinutes, then DailyTime.
+type PlanConfig struct {
+	Cron            string
+	Times           []string
+	Timezone        string
+	IntervalMinutes int
+	DailyTime       string
+}
+
+// NewPlan validates cfg and returns the plan it selects.
+func NewPlan(cfg PlanConfig) (Plan, error) {
+	cron := strings.TrimSpace(cfg.Cron)
+	if cron != "" && len(cfg.Times) > 0 {
+		return nil, &runErr{"ingest_cron and ingest_times are mutually exclusive"}
+	}
+	loc := time.Local
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("ingest_timezone: %w", err)
+		}
+		loc = l
+	}
+	switch {
+	case cron != "":
+		spec, err := parseCron(cron, loc)
+		if err != nil {
+			return nil, fmt.Errorf("ingest_cron: %w", err)
+		}
+		return spec, nil
+	case len(cfg.Times) > 0:
+		plan, err := newDailyPlan(cfg.Times, loc)
+		if err != nil {
+			return nil, fmt.Errorf("ingest_times: %w", err)
+		}
+		return plan, nil
+	case cfg.IntervalMinutes > 0:
+		return intervalPlan(time.Duration(cfg.IntervalMinutes) * time.Minute), nil
+	default:
+		plan, err := newDailyPlan([]string{cfg.DailyTime}, loc)
+		if err != nil {
+			return nil, fmt.Errorf("daily_ingest_time: %w", err)
+		}
+		return plan, nil
+	}
+}
+
+// intervalPlan runs a fixed time after the previous run finished.
+type intervalPlan time.Duration
+
+func (p intervalPlan) Next(t time.Time) time.Time { return t.Add(time.Duration(p)) }
+
+func (p intervalPlan) String() string { return "every " + time.Duration(p).String() }
+
+// dailyPlan runs at fixed wall-clock times every day.
+type dailyPlan struct {
+	minutes []int // minutes after midnight, sorted
+	loc     *time.Location
+}
+
+func newDailyPlan(times []string, loc *time.Location) (dailyPlan, error) {
+	p := dailyPlan{loc: loc}
+	for _, hhmm := range times {
+		m, err := parseHHMM(hhmm)
+		if err != nil {
+			return dailyPlan{}, err
+		}
+		if !slices.Contains(p.minutes, m) {
+			p.minutes = append(p.minutes, m)
+		}
+	}
+	slices.Sort(p.minutes)
+	return p, nil
+}
+
+func (p dailyPlan) Next(t time.Time) time.Time {
+	local := t.In(p.loc)
+	for day := 0; day <= 2; day++ {
+		for _, m := range p.minutes {
+			c, ok := wallClock(time.Date(local.Year(), local.Month(), local.Day()+day, m/60, m%60, 0, 0, time.UTC), p.loc)
+			if ok && c.After(t) {
+				return c
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (p dailyPlan) String() string {
+	parts := make([]string, len(p.minutes))
+	for i, m := range p.minutes {
+		parts[i] = fmt.Sprintf("%02d:%02d", m/60, m%60)
+	}
+	return "daily at " + strings.Join(parts, ", ") + " (" + p.loc.String() + ")"
+}
+
+func parseHHMM(hhmm string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(hhmm), ":")
+	if len(parts) != 2 {
+		return 0, &runErr{fmt.Sprintf("time %q must be HH:MM", hhmm)}
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 || h > 23 {
+		return 0, &runErr{fmt.Sprintf("invalid hour in %q", hhmm)}
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 {
+		return 0, &runErr{fmt.Sprintf("invalid minute in %q", hhmm)}
+	}
+	return h*60 + m, nil
+}
@@ -3,8 +3,6 @@ package scheduler
 import (
 	"context"
 	"log"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 )
@@ -14,45 +12,50 @@ type Runner interface {
 }
 
 type Scheduler struct {
-	dailyHHMM string
-	interval  time.Duration
+	plan      Plan
 	runner    Runner
 	mu        sync.Mutex
 	running   bool
 	state     RunState
+	jobs      []*Job
+	current   *Job
+	nextJobID int64
+	cancelRun context.CancelFunc
+	cancelled bool
+	nextRun   time.Time
 }
 
-func New(dailyHHMM string, intervalMinutes int, runner Runner) *Scheduler {
-	var d time.Duration
-	if intervalMinutes > 0 {
-		d = time.Duration(intervalMinutes) * time.Minute
-	}
-	return &Scheduler{dailyHHMM: dailyHHMM, interval: d, runner: runner}
+func New(plan Plan, runner Runner) *Scheduler {
+	return &Scheduler{plan: plan, runner: runner}
 }
 
 type RunState struct {
 	Running         bool      `json:"running"`
+	JobID           int64     `json:"job_id"`
 	CurrentSource   string    `json:"current_source"`
 	StartedAt       time.Time `json:"started_at"`
 	LastCompletedAt time.Time `json:"last_completed_at"`
 	LastDurationMS  int64     `json:"last_duration_ms"`
 	LastError       string    `json:"last_error"`
+	LastCancelled   bool      `json:"last_cancelled"`
 	LastSource      string    `json:"last_source"`
 }
 
 func (s *Scheduler) Start(ctx context.Context) {
-	go func() {
-		if s.interval > 0 {
-			s.startInterval(ctx)
-			return
-		}
-		s.startDaily(ctx)
-	}()
+	go s.loop(ctx)
 }
 
-func (s *Scheduler) startInterval(ctx context.Context) {
-	next := time.Now().Add(s.interval)
+func (s *Scheduler) loop(ctx context.Context) {
+	log.Printf("scheduler: plan %s", s.plan)
 	for {
+		next := s.plan.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("scheduler: no further runs planned")
+			return
+		}
+		s.mu.Lock()
+		s.nextRun = next
+		s.mu.Unlock()
 		wait := time.Until(next)
 		if wait < 0 {
 			wait = 0
@@ -66,74 +69,151 @@ func (s *Scheduler) startInterval(ctx context.Context) {
 		if err := s.run(ctx, "scheduled"); err != nil {
 			log.Printf("scheduler: ingestion run error: %v", err)
 		}
-		next = time.Now().Add(s.interval)
 	}
 }
 
-func (s *Scheduler) startDaily(ctx context.Context) {
-	for {
-		next, err := nextRun(time.Now(), s.dailyHHMM)
-		if err != nil {
-			log.Printf("scheduler: invalid daily time %q: %v", s.dailyHHMM, err)
-			return
-		}
-		wait := time.Until(next)
-		if wait < 0 {
-			wait = 0
-		}
-		log.Printf("scheduler: next ingestion at %s", next.Format(time.RFC3339))
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(wait):
-		}
-		if err := s.run(ctx, "scheduled"); err != nil {
-			log.Printf("scheduler: ingestion run error: %v", err)
-		}
+// Schedule describes the plan and its next n run times. For interval plans
+// the times after the first assume each run takes no time.
+func (s *Scheduler) Schedule(n int) ScheduleInfo {
+	s.mu.Lock()
+	next := s.nextRun
+	s.mu.Unlock()
+	now := time.Now()
+	if !next.After(now) {
+		next = s.plan.Next(now)
 	}
+	info := ScheduleInfo{Plan: s.plan.String(), NextRuns: make([]time.Time, 0, n)}
+	for len(info.NextRuns) < n && !next.IsZero() {
+		info.NextRuns = append(info.NextRuns, next)
+		next = s.plan.Next(next)
+	}
+	return info
+}
+
+type ScheduleInfo struct {
+	Plan     string      `json:"plan"`
+	NextRuns []time.Time `json:"next_runs"`
 }
 
-func (s *Scheduler) RunNow(ctx context.Context) error {
-	return s.run(ctx, "manual")
+// StartNow starts a manual run in the background and returns its job right
+// away. Like a scheduled run it has no time limit; Cancel stops it.
+func (s *Scheduler) StartNow() (Job, error) {
+	job, runCtx, err := s.begin(context.Background(), "manual")
+	if err != nil {
+		return Job{}, err
+	}
+	go func() {
+		_ = s.execute(runCtx, job)
+	}()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *job, nil
 }
 
 func (s *Scheduler) run(ctx context.Context, source string) error {
+	job, runCtx, err := s.begin(ctx, source)
+	if err != nil {
+		return err
+	}
+	return s.execute(runCtx, job)
+}
+
+// begin registers a new run and returns the context it must run under; Cancel
+// cancels that context.
+func (s *Scheduler) begin(ctx context.Context, source string) (*Job, context.Context, error) {
 	const minRunGap = 15 * time.Second
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.running {
-		s.mu.Unlock()
-		return ErrIngestAlreadyRunning
+		return nil, nil, ErrIngestAlreadyRunning
 	}
 	if !s.state.LastCompletedAt.IsZero() {
 		sinceLast := time.Since(s.state.LastCompletedAt)
 		if sinceLast < minRunGap {
-			s.mu.Unlock()
-			return ErrIngestCooldown
+			return nil, nil, ErrIngestCooldown
 		}
 	}
+	now := time.Now()
+	job := s.newJob(source, now)
+	runCtx, cancel := context.WithCancel(context.WithValue(ctx, jobCtxKey{}, jobRef{id: job.ID, source: source}))
+	s.cancelRun = cancel
+	s.cancelled = false
 	s.running = true
 	s.state.Running = true
+	s.state.JobID = job.ID
 	s.state.CurrentSource = source
-	s.state.StartedAt = time.Now()
+	s.state.StartedAt = now
+	return job, runCtx, nil
+}
+
+// Exclusive runs fn while no ingestion can start. It returns
+// ErrIngestAlreadyRunning without calling fn when a run is in progress; runs
+// that come due meanwhile fail the same way and are skipped.
+func (s *Scheduler) Exclusive(fn func() error) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return ErrIngestAlreadyRunning
+	}
+	s.running = true
 	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+	return fn()
+}
 
-	log.Printf("scheduler: ingestion started (source=%s)", source)
+// Cancel stops the running ingestion. The runner keeps what it stored so far
+// and the run is recorded as cancelled.
+func (s *Scheduler) Cancel() (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running || s.cancelRun == nil || s.current == nil {
+		return Job{}, ErrIngestNotRunning
+	}
+	if !s.cancelled {
+		s.cancelled = true
+		s.current.addLine("scheduler: cancel requested")
+		s.cancelRun()
+	}
+	return *s.current, nil
+}
+
+func (s *Scheduler) execute(ctx context.Context, job *Job) error {
+	source := job.Source
+	log.Printf("scheduler: ingestion started (source=%s, job=%d)", source, job.ID)
 	start := time.Now()
 	err := s.runner.Run(ctx)
 
 	defer func() {
 		s.mu.Lock()
+		s.cancelRun()
+		s.cancelRun = nil
+		cancelled := s.cancelled
 		s.running = false
 		s.state.Running = false
 		s.state.CurrentSource = ""
 		s.state.LastCompletedAt = time.Now()
 		s.state.LastDurationMS = time.Since(start).Milliseconds()
 		s.state.LastSource = source
-		if err != nil {
+		s.state.LastCancelled = cancelled
+		if cancelled {
+			s.state.LastError = "cancelled"
+			job.State = JobCancelled
+			job.Error = "cancelled"
+		} else if err != nil {
 			s.state.LastError = err.Error()
+			job.State = JobFailed
+			job.Error = err.Error()
 		} else {
 			s.state.LastError = ""
+			job.State = JobDone
 		}
+		job.FinishedAt = s.state.LastCompletedAt
+		s.current = nil
+		job.touch()
 		s.mu.Unlock()
 	}()
 	if err != nil {
@@ -153,29 +233,9 @@ func (s *Scheduler) Snapshot() RunState {
 var (
 	ErrIngestAlreadyRunning = &runErr{"ingestion already running"}
 	ErrIngestCooldown       = &runErr{"ingestion just completed; wait a few seconds before starting again"}
+	ErrIngestNotRunning     = &runErr{"no ingestion is running"}
 )
 
 type runErr struct{ msg string }
 
 func (e *runErr) Error() string { return e.msg }
-
-func nextRun(now time.Time, hhmm string) (time.Time, error) {
-	parts := strings.Split(hhmm, ":")
-	if len(parts) != 2 {
-		return time.Time{}, &runErr{"daily_ingest_time must be HH:MM"}
-	}
-	h, err := strconv.Atoi(parts[0])
-	if err != nil || h < 0 || h > 23 {
-		return time.Time{}, &runErr{"invalid hour"}
-	}
-	m, err := strconv.Atoi(parts[1])
-	if err != nil || m < 0 || m > 59 {
-		return time.Time{}, &runErr{"invalid minute"}
-	}
-	loc := now.Location()
-	t := time.Date(now.Year(), now.Month(), now.Day(), h, m, 0, 0, loc)
-	if !t.After(now) {
-		t = t.Add(24 * time.Hour)
-	}
-	return t, nil
-}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/auth"
+	"discover/internal/model"
+)
+
+// Fever API compatibility layer (https://feedafever.com/api, version 3).
+// Topics are exposed as both groups and feeds, so each article belongs to the
+// feed of the lowest-id topic that found it. "useful" maps to saved and
+// "read"/"seen" map to read.
+
+const feverFaviconID = 1
+
+// 1x1 transparent GIF used as the single shared favicon.
+const feverFaviconData = "image/gif;base64,R0lGODlhAQABAIAAAAAAAP///yH5BAEAAAAALAAAAAABAAEAAAIBRAA7"
+
+type feverGroup struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feverFeedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	URL               string `json:"url"`
+	SiteURL           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	URL           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+func (a *API) handleFever(w http.ResponseWriter, r *http.Request) {
+	if !a.cfg.FeverAPIEnabled {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxBody := a.cfg.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = 1 << 20
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+	if err := r.ParseForm(); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	q := r.URL.Query()
+	if _, ok := q["api"]; !ok {
+		respondErr(w, http.StatusBadRequest, errors.New("missing api parameter"))
+		return
+	}
+	resp := map[string]any{"api_version": 3, "auth": 0}
+	if err := a.user.ValidateFeverKey(r.PostForm.Get("api_key"), r.RemoteAddr); err != nil {
+		if errors.Is(err, auth.ErrUserBlocked) {
+			respondErr(w, http.StatusTooManyRequests, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, resp)
+		return
+	}
+	resp["auth"] = 1
+	resp["last_refreshed_on_time"] = unixOrZero(a.scheduler.Snapshot().LastCompletedAt)
+
+	ctx := r.Context()
+	if mark := r.PostForm.Get("mark"); mark != "" {
+		if err := a.feverMark(ctx, mark, r.PostForm.Get("as"), r.PostForm.Get("id"), r.PostForm.Get("before")); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	_, wantGroups := q["groups"]
+	_, wantFeeds := q["feeds"]
+	if wantGroups || wantFeeds {
+		topics, err := a.store.ListTopics(ctx)
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		feedsGroups := make([]feverFeedsGroup, 0, len(topics))
+		for _, t := range topics {
+			feedsGroups = append(feedsGroups, feverFeedsGroup{GroupID: t.ID, FeedIDs: strconv.FormatInt(t.ID, 10)})
+		}
+		resp["feeds_groups"] = feedsGroups
+		if wantGroups {
+			groups := make([]feverGroup, 0, len(topics))
+			for _, t := range topics {
+				groups = append(groups, feverGroup{ID: t.ID, Title: t.Query})
+			}
+			resp["groups"] = groups
+		}
+		if wantFeeds {
+			updated := unixOrZero(a.scheduler.Snapshot().LastCompletedAt)
+			feeds := make([]feverFeed, 0, len(topics))
+			for _, t := range topics {
+				f := feverFeed{ID: t.ID, FaviconID: feverFaviconID, Title: t.Query, LastUpdatedOnTime: updated}
+				if t.Kind == model.TopicKindFeed {
+					f.URL = t.Query
+					f.SiteURL = t.Query
+				}
+				feeds = append(feeds, f)
+			}
+			resp["feeds"] = feeds
+		}
+	}
+	if _, ok := q["favicons"]; ok {
+		resp["favicons"] = []map[string]any{{"id": feverFaviconID, "data": feverFaviconData}}
+	}
+	if _, ok := q["links"]; ok {
+		resp["links"] = []any{}
+	}
+	if _, ok := q["unread_item_ids"]; ok {
+		ids, err := a.store.ArticleIDsByStatus(ctx, model.StatusUnread)
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp["unread_item_ids"] = joinIDs(ids)
+	}
+	if _, ok := q["saved_item_ids"]; ok {
+		ids, err := a.store.ArticleIDsByStatus(ctx, model.StatusUseful)
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp["saved_item_ids"] = joinIDs(ids)
+	}
+	if _, ok := q["items"]; ok {
+		items, total, err := a.feverItems(ctx, q.Get("since_id"), q.Get("max_id"), q.Get("with_ids"))
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp["items"] = items
+		resp["total_items"] = total
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (a *API) feverItems(ctx context.Context, sinceRaw, maxRaw, withRaw string) ([]feverItem, int, error) {
+	const pageSize = 50
+	var articles []model.Article
+	var err error
+	if withRaw != "" {
+		ids := parseIDList(withRaw)
+		if len(ids) > pageSize {
+			ids = ids[:pageSize]
+		}
+		articles, err = a.store.ListArticlesByIDs(ctx, ids)
+	} else {
+		sinceID, _ := strconv.ParseInt(sinceRaw, 10, 64)
+		maxID, _ := strconv.ParseInt(maxRaw, 10, 64)
+		articles, err = a.store.ListSyncArticles(ctx, sinceID, maxID, pageSize)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := a.store.CountSyncArticles(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]int64, len(articles))
+	for i, art := range articles {
+		ids[i] = art.ID
+	}
+	feedIDs, err := a.store.PrimaryTopicIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]feverItem, 0, len(articles))
+	for _, art := range articles {
+		created := art.PublishedAt
+		if created.IsZero() {
+			created = art.IngestedAt
+		}
+		it := feverItem{
+			ID:            art.ID,
+			FeedID:        feedIDs[art.ID],
+			Title:         art.Title,
+			Author:        art.SourceDomain,
+			HTML:          feverHTML(art),
+			URL:           art.URL,
+			CreatedOnTime: unixOrZero(created),
+		}
+		if art.Status == model.StatusUseful {
+			it.IsSaved = 1
+		}
+		if art.Status != model.StatusUnread {
+			it.IsRead = 1
+		}
+		out = append(out, it)
+	}
+	return out, total, nil
+}
+
+func (a *API) feverMark(ctx context.Context, mark, as, idRaw, beforeRaw string) error {
+	id, err := strconv.ParseInt(strings.TrimSpace(idRaw), 10, 64)
+	if err != nil {
+		return errors.New("invalid id")
+	}
+	switch mark {
+	case "item":
+		art, err := a.store.GetArticle(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch as {
+		case "read":
+			if art.Status == model.StatusUnread || art.Status == model.StatusSeen {
+				return a.store.MarkRead(ctx, id, model.StatusSourceFever)
+			}
+			return nil
+		case "unread":
+			if art.Status == model.StatusRead || art.Status == model.StatusSeen {
+				return a.store.MarkIDStatus(ctx, id, model.StatusUnread, 0, model.StatusSourceFever)
+			}
+			return nil
+		case "saved":
+			if art.Status != model.StatusUseful {
+				return a.store.MarkIDStatus(ctx, id, model.StatusUseful, 1.0, model.StatusSourceFever)
+			}
+			return nil
+		case "unsaved":
+			if art.Status == model.StatusUseful {
+				return a.store.MarkIDStatus(ctx, id, model.StatusRead, -1.0, model.StatusSourceFever)
+			}
+			return nil
+		default:
+			return errors.New("invalid as")
+		}
+	case "feed", "group":
+		if as != "read" {
+			return errors.New("invalid as")
+		}
+		var before time.Time
+		if secs, err := strconv.ParseInt(strings.TrimSpace(beforeRaw), 10, 64); err == nil && secs > 0 {
+			before = time.Unix(secs, 0)
+		}
+		ids, err := a.store.UnreadIDsForTopic(ctx, id, before)
+		if err != nil {
+			return err
+		}
+		return a.store.MarkIDsRead(ctx, ids, model.StatusSourceFever)
+	default:
+		return errors.New("invalid mark")
+	}
+}
+
+func feverHTML(art model.Article) string {
+	var b strings.Builder
+	if art.ThumbnailURL != "" {
+		b.WriteString(`<p><img src="`)
+		b.WriteString(html.EscapeString(art.ThumbnailURL))
+		b.WriteString(`" alt=""></p>`)
+	}
+	b.WriteString("<p>")
+	b.WriteString(html.EscapeString(art.Content))
+	b.WriteString("</p><p><small>")
+	b.WriteString(html.EscapeString(firstNonEmptyString(art.SourceDomain, "unknown")))
+	b.WriteString(" | score ")
+	b.WriteString(strconv.FormatFloat(art.Score, 'f', 2, 64))
+	b.WriteString("</small></p>")
+	return b.String()
+}
+
+func parseIDList(raw string) []int64 {
+	parts := strings.Split(raw, ",")
+	out := make([]int64, 0, len(parts))
+	for _, p := range parts {
+		if id, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64); err == nil && id > 0 {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+	"discover/internal/store"
+)
+
+// historyStatuses is the combined view: everything that has left unread.
+var historyStatuses = []model.ArticleStatus{model.StatusUseful, model.StatusRead, model.StatusSeen, model.StatusHidden}
+
+func (a *API) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	statuses, err := parseStatuses(q.Get("status"))
+	if err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(statuses) == 0 {
+		statuses = historyStatuses
+	}
+	cursor, err := parseHistoryCursor(q.Get("cursor"))
+	if err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	limit := 20
+	if s := q.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	items, more, err := a.store.ListHistory(r.Context(), statuses, cursor, limit)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := map[string]any{"items": items}
+	if more && len(items) > 0 {
+		last := items[len(items)-1]
+		resp["next_cursor"] = fmt.Sprintf("%d_%d", last.StatusChangedAt.Unix(), last.ID)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseHistoryCursor reads the "<unix seconds>_<id>" cursor returned as
+// next_cursor.
+func parseHistoryCursor(raw string) (store.HistoryCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return store.HistoryCursor{}, nil
+	}
+	secs, id, ok := strings.Cut(raw, "_")
+	if !ok {
+		return store.HistoryCursor{}, errors.New("invalid cursor")
+	}
+	unix, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return store.HistoryCursor{}, errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n <= 0 {
+		return store.HistoryCursor{}, errors.New("invalid cursor")
+	}
+	return store.HistoryCursor{ChangedAt: time.Unix(unix, 0), ID: n}, nil
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"discover/internal/model"
+	"discover/internal/store"
+)
+
+const defaultProbeQuery = "news"
+
+// handleAdminInstances lists, adds/updates and deletes the search instances
+// topics are fetched from. Instances are keyed by URL, as topics are by query.
+func (a *API) handleAdminInstances(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		instances, err := a.store.ListSearxInstances(r.Context())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		health, err := a.store.InstanceHealthMap(r.Context())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		for i := range instances {
+			instances[i].APIKey = ""
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"items": instances, "health": health})
+	case http.MethodPost:
+		var req model.SearxInstance
+		if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		inst, err := normalizeInstance(req)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := a.store.UpsertSearxInstance(r.Context(), inst); err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := a.store.DeleteSearxInstance(r.Context(), id); err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminInstanceProbe runs a test search against a saved instance (id)
+// or an unsaved one (url, provider, api_key and extra_params), so an instance
+// can be checked before it is added.
+func (a *API) handleAdminInstanceProbe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		ID          int64  `json:"id"`
+		URL         string `json:"url"`
+		Provider    string `json:"provider"`
+		APIKey      string `json:"api_key"`
+		ExtraParams string `json:"extra_params"`
+		Query       string `json:"query"`
+	}
+	if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	var inst model.SearxInstance
+	if req.ID != 0 {
+		saved, err := a.store.GetSearxInstance(r.Context(), req.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondErr(w, http.StatusNotFound, errors.New("instance not found"))
+			return
+		}
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		inst = saved
+	} else {
+		checked, err := normalizeInstance(model.SearxInstance{URL: req.URL, Provider: req.Provider, APIKey: req.APIKey, ExtraParams: req.ExtraParams})
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		inst = checked
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		query = defaultProbeQuery
+	}
+	if len(query) > 200 {
+		respondErr(w, http.StatusBadRequest, errors.New("query too long"))
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"probe": a.prober.Probe(r.Context(), inst, query)})
+}
+
+// normalizeInstance validates an instance from the admin API and returns it
+// with the URL trimmed of its trailing slash, the form ingest and health use.
+func normalizeInstance(inst model.SearxInstance) (model.SearxInstance, error) {
+	inst.URL = strings.TrimRight(strings.TrimSpace(inst.URL), "/")
+	u, err := url.Parse(inst.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return inst, errors.New("url must be an http(s) base URL")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return inst, errors.New("url must not have a query or fragment; use extra_params")
+	}
+	provider, err := store.NormalizeProvider(inst.Provider)
+	if err != nil {
+		return inst, err
+	}
+	inst.Provider = provider
+	inst.APIKey = strings.TrimSpace(inst.APIKey)
+	if len(inst.APIKey) > 200 {
+		return inst, errors.New("api_key too long")
+	}
+	if inst.Priority < -100 || inst.Priority > 100 {
+		return inst, errors.New("priority must be -100..100")
+	}
+	if inst.DelaySeconds < 0 || inst.DelaySeconds > 3600 {
+		return inst, errors.New("delay_seconds must be 0..3600")
+	}
+	inst.ExtraParams = strings.TrimPrefix(strings.TrimSpace(inst.ExtraParams), "?")
+	if len(inst.ExtraParams) > 1000 {
+		return inst, errors.New("extra_params too long")
+	}
+	if _, err := url.ParseQuery(inst.ExtraParams); err != nil {
+		return inst, errors.New("extra_params must be a query string such as safesearch=0&theme=simple")
+	}
+	return inst, nil
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/scheduler"
+)
+
+func (a *API) startIngestJob(w http.ResponseWriter) {
+	job, err := a.scheduler.StartNow()
+	if err != nil {
+		if errors.Is(err, scheduler.ErrIngestAlreadyRunning) || errors.Is(err, scheduler.ErrIngestCooldown) {
+			respondErr(w, http.StatusConflict, err)
+			return
+		}
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, map[string]any{"ok": true, "job": job})
+}
+
+func (a *API) handleAdminIngestCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	job, err := a.scheduler.Cancel()
+	if err != nil {
+		if errors.Is(err, scheduler.ErrIngestNotRunning) {
+			respondErr(w, http.StatusConflict, err)
+			return
+		}
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"ok": true, "job": job})
+}
+
+// respondJob reports one job's state without its log; id 0 or no id means
+// the latest job.
+func (a *API) respondJob(w http.ResponseWriter, r *http.Request) {
+	id, err := jobIDParam(r)
+	if err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	job, _, ok := a.scheduler.Job(id)
+	if !ok {
+		respondErr(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"job": job})
+}
+
+func (a *API) handleAdminIngestJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Query().Get("id") == "" {
+		respondJSON(w, http.StatusOK, map[string]any{"jobs": a.scheduler.Jobs()})
+		return
+	}
+	id, err := jobIDParam(r)
+	if err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	job, lines, ok := a.scheduler.Job(id)
+	if !ok {
+		respondErr(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"job": job, "lines": lines})
+}
+
+// handleAdminIngestEvents streams a job's progress as Server-Sent Events:
+// a "job" event with the current state, one "line" event per log line (id is
+// the line number, so a reconnecting EventSource resumes via Last-Event-ID)
+// and a final "done" event once the job has finished.
+func (a *API) handleAdminIngestEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := jobIDParam(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	from := 0
+	if n, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && n > 0 {
+		from = n
+	}
+	job, lines, changed, ok := a.scheduler.WatchJob(id, from)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		respondErr(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	id = job.ID
+
+	// The server write timeout would cut the stream off mid-run.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(20 * time.Second)
+	defer keepAlive.Stop()
+	writeSSE(w, "job", "", job)
+	for {
+		for i, line := range lines {
+			writeSSE(w, "line", strconv.Itoa(from+i+1), line)
+		}
+		from += len(lines)
+		if job.State != scheduler.JobRunning {
+			writeSSE(w, "done", "", job)
+			_ = rc.Flush()
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-changed:
+		}
+		job, lines, changed, _ = a.scheduler.WatchJob(id, from)
+	}
+}
+
+func writeSSE(w http.ResponseWriter, event, id string, v any) {
+	var data string
+	if s, ok := v.(string); ok {
+		data = s
+	} else {
+		b, _ := json.Marshal(v)
+		data = string(b)
+	}
+	fmt.Fprintf(w, "event: %s\n", event)
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	for _, part := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", part)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func jobIDParam(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("id"))
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
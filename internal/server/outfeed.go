@@ -0,0 +1,294 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+const (
+	discoverNS = "https://github.com/luxzg/discover/ns/feed"
+	mediaNS    = "http://search.yahoo.com/mrss/"
+)
+
+type outputItem struct {
+	model.Article
+	Topics []string
+}
+
+type atomFeed struct {
+	XMLName    xml.Name    `xml:"feed"`
+	XMLNS      string      `xml:"xmlns,attr"`
+	DiscoverNS string      `xml:"xmlns:discover,attr"`
+	ID         string      `xml:"id"`
+	Title      string      `xml:"title"`
+	Updated    string      `xml:"updated"`
+	Links      []atomLink  `xml:"link"`
+	Generator  string      `xml:"generator"`
+	Entries    []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary"`
+	Categories []atomCategory `xml:"category"`
+	Score      string         `xml:"discover:score"`
+	Domain     string         `xml:"discover:source_domain"`
+	Thumbnail  string         `xml:"discover:thumbnail,omitempty"`
+}
+
+type rssFeed struct {
+	XMLName    xml.Name   `xml:"rss"`
+	Version    string     `xml:"version,attr"`
+	DiscoverNS string     `xml:"xmlns:discover,attr"`
+	MediaNS    string     `xml:"xmlns:media,attr"`
+	Channel    rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type rssMediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
+
+type rssItem struct {
+	Title       string             `xml:"title"`
+	Link        string             `xml:"link"`
+	GUID        rssGUID            `xml:"guid"`
+	PubDate     string             `xml:"pubDate"`
+	Description string             `xml:"description"`
+	Categories  []string           `xml:"category"`
+	Enclosure   *rssEnclosure      `xml:"enclosure,omitempty"`
+	Thumbnail   *rssMediaThumbnail `xml:"media:thumbnail,omitempty"`
+	Score       string             `xml:"discover:score"`
+	Domain      string             `xml:"discover:source_domain"`
+}
+
+func (a *API) handleOutputFeed(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ok, err := a.store.ValidateFeedToken(r.Context(), r.URL.Query().Get("token"))
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		limit := a.cfg.OutputFeedLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 200 {
+				limit = n
+			}
+		}
+		items, err := a.outputItems(r, limit)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		var payload any
+		contentType := "application/atom+xml; charset=utf-8"
+		if format == "rss" {
+			payload = buildRSS(items, requestBaseURL(r))
+			contentType = "application/rss+xml; charset=utf-8"
+		} else {
+			payload = buildAtom(items, requestBaseURL(r), r.URL.RequestURI())
+		}
+		body, err := xml.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(xml.Header))
+		_, _ = w.Write(body)
+	}
+}
+
+func (a *API) outputItems(r *http.Request, limit int) ([]outputItem, error) {
+	articles, err := a.store.FetchTopUnread(r.Context(), limit, a.cfg.FeedMinScore, a.cfg.RecencyHalfLifeHours)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(articles))
+	for i, art := range articles {
+		ids[i] = art.ID
+	}
+	topics, err := a.store.TopicQueriesForArticles(r.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]outputItem, len(articles))
+	for i, art := range articles {
+		out[i] = outputItem{Article: art, Topics: topics[art.ID]}
+	}
+	return out, nil
+}
+
+func buildAtom(items []outputItem, base, self string) atomFeed {
+	updated := time.Now().UTC()
+	feed := atomFeed{
+		XMLNS:      "http://www.w3.org/2005/Atom",
+		DiscoverNS: discoverNS,
+		ID:         base + "/",
+		Title:      "Discover",
+		Updated:    updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: base + "/", Rel: "alternate", Type: "text/html"},
+			{Href: base + self, Rel: "self", Type: "application/atom+xml"},
+		},
+		Generator: "discover",
+	}
+	for _, it := range items {
+		pub := it.PublishedAt
+		if pub.IsZero() {
+			pub = it.IngestedAt
+		}
+		e := atomEntry{
+			ID:        "urn:discover:article:" + it.URLHash,
+			Title:     it.Title,
+			Links:     []atomLink{{Href: it.URL, Rel: "alternate", Type: "text/html"}},
+			Published: pub.UTC().Format(time.RFC3339),
+			Updated:   pub.UTC().Format(time.RFC3339),
+			Summary:   outputSummary(it),
+			Score:     strconv.FormatFloat(it.Score, 'f', 2, 64),
+			Domain:    it.SourceDomain,
+			Thumbnail: it.ThumbnailURL,
+		}
+		for _, t := range it.Topics {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	return feed
+}
+
+func buildRSS(items []outputItem, base string) rssFeed {
+	feed := rssFeed{
+		Version:    "2.0",
+		DiscoverNS: discoverNS,
+		MediaNS:    mediaNS,
+		Channel: rssChannel{
+			Title:         "Discover",
+			Link:          base + "/",
+			Description:   "Top-ranked unread articles",
+			LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+			Generator:     "discover",
+		},
+	}
+	for _, it := range items {
+		pub := it.PublishedAt
+		if pub.IsZero() {
+			pub = it.IngestedAt
+		}
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{Value: "urn:discover:article:" + it.URLHash, IsPermaLink: "false"},
+			PubDate:     pub.UTC().Format(time.RFC1123Z),
+			Description: outputSummary(it),
+			Categories:  it.Topics,
+			Score:       strconv.FormatFloat(it.Score, 'f', 2, 64),
+			Domain:      it.SourceDomain,
+		}
+		if it.ThumbnailURL != "" {
+			item.Thumbnail = &rssMediaThumbnail{URL: it.ThumbnailURL}
+			// The size is unknown without fetching the image; readers
+			// accept 0.
+			if typ := imageType(it.ThumbnailURL); typ != "" {
+				item.Enclosure = &rssEnclosure{URL: it.ThumbnailURL, Type: typ, Length: "0"}
+			}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return feed
+}
+
+// imageType guesses an image's media type from the extension of its URL
+// path, or returns "" when there is none or it is not an image.
+func imageType(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	typ, _, err := mime.ParseMediaType(mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))))
+	if err != nil || !strings.HasPrefix(typ, "image/") {
+		return ""
+	}
+	return typ
+}
+
+func outputSummary(it outputItem) string {
+	meta := fmt.Sprintf("%s | score %.2f", firstNonEmptyString(it.SourceDomain, "unknown"), it.Score)
+	if len(it.Topics) > 0 {
+		meta += " | topics: " + strings.Join(it.Topics, ", ")
+	}
+	if strings.TrimSpace(it.Content) == "" {
+		return meta
+	}
+	return meta + "\n\n" + it.Content
+}
+
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// handleAdminRuns lists recent ingest runs with per-topic health, or one run
+// with its per-topic outcomes when ?id= is given.
+func (a *API) handleAdminRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("id") != "" {
+		id, err := jobIDParam(r)
+		if err != nil || id == 0 {
+			respondErr(w, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+		run, err := a.store.GetIngestRun(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondErr(w, http.StatusNotFound, errors.New("run not found"))
+			return
+		}
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		topics, err := a.store.ListIngestRunTopics(r.Context(), id)
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"run": run, "topics": topics})
+		return
+	}
+	limit := 20
+	if s := q.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	runs, err := a.store.ListIngestRuns(r.Context(), limit)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	stats, err := a.store.TopicRunStats(r.Context())
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"runs": runs, "topics": stats})
+}
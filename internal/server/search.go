@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+	"discover/internal/store"
+)
+
+func (a *API) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	p := store.SearchParams{Query: strings.TrimSpace(q.Get("q")), Limit: 20}
+	if p.Query == "" {
+		respondErr(w, http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+	var err error
+	if p.Statuses, err = parseStatuses(q.Get("status")); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if p.From, err = parseDateParam(q.Get("from")); err != nil {
+		respondErr(w, http.StatusBadRequest, fmt.Errorf("from: %w", err))
+		return
+	}
+	if p.To, err = parseDateParam(q.Get("to")); err != nil {
+		respondErr(w, http.StatusBadRequest, fmt.Errorf("to: %w", err))
+		return
+	}
+	if !p.To.IsZero() {
+		p.To = p.To.AddDate(0, 0, 1)
+	}
+	if s := q.Get("topic_id"); s != "" {
+		if p.TopicID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			respondErr(w, http.StatusBadRequest, errors.New("invalid topic_id"))
+			return
+		}
+	}
+	switch q.Get("sort") {
+	case "", "relevance":
+	case "date":
+		p.SortDate = true
+	default:
+		respondErr(w, http.StatusBadRequest, errors.New("sort must be relevance or date"))
+		return
+	}
+	if s := q.Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= 100 {
+			p.Limit = n
+		}
+	}
+	if s := q.Get("offset"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			p.Offset = n
+		}
+	}
+	items, more, err := a.store.SearchArticles(r.Context(), p)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := map[string]any{"items": items}
+	if more {
+		resp["next_offset"] = p.Offset + len(items)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// parseStatuses reads a comma-separated status filter; empty or "all" means
+// no filter.
+func parseStatuses(raw string) ([]model.ArticleStatus, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "all" {
+		return nil, nil
+	}
+	var out []model.ArticleStatus
+	for _, part := range strings.Split(raw, ",") {
+		st := model.ArticleStatus(strings.ToLower(strings.TrimSpace(part)))
+		switch st {
+		case model.StatusUnread, model.StatusSeen, model.StatusRead, model.StatusUseful, model.StatusHidden:
+			out = append(out, st)
+		default:
+			return nil, fmt.Errorf("unknown status %q", part)
+		}
+	}
+	return out, nil
+}
+
+func parseDateParam(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, errors.New("date must be YYYY-MM-DD")
+	}
+	return t, nil
+}
@@ -2,16 +2,23 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"discover/internal/auth"
 	"discover/internal/config"
+	"discover/internal/matcher"
 	"discover/internal/model"
+	"discover/internal/opml"
 	"discover/internal/scheduler"
 	"discover/internal/store"
 )
@@ -21,6 +28,7 @@ type API struct {
 	store     *store.Store
 	scheduler *scheduler.Scheduler
 	progress  progressSource
+	prober    instanceProber
 	guard     *auth.Guard
 	user      *auth.UserGuard
 	assets    http.Handler
@@ -30,8 +38,12 @@ type progressSource interface {
 	LastProgress() (string, time.Time)
 }
 
-func New(cfg config.Config, st *store.Store, sched *scheduler.Scheduler, progress progressSource, guard *auth.Guard, user *auth.UserGuard, assets http.Handler) *API {
-	return &API{cfg: cfg, store: st, scheduler: sched, progress: progress, guard: guard, user: user, assets: assets}
+type instanceProber interface {
+	Probe(ctx context.Context, inst model.SearxInstance, query string) model.InstanceProbe
+}
+
+func New(cfg config.Config, st *store.Store, sched *scheduler.Scheduler, progress progressSource, prober instanceProber, guard *auth.Guard, user *auth.UserGuard, assets http.Handler) *API {
+	return &API{cfg: cfg, store: st, scheduler: sched, progress: progress, prober: prober, guard: guard, user: user, assets: assets}
 }
 
 func (a *API) Routes() http.Handler {
@@ -40,6 +52,11 @@ func (a *API) Routes() http.Handler {
 	mux.HandleFunc("/", a.serveFeedUI)
 	mux.HandleFunc("/admin", a.serveAdminUI)
 
+	mux.HandleFunc("/feeds/atom", a.handleOutputFeed("atom"))
+	mux.HandleFunc("/feeds/rss", a.handleOutputFeed("rss"))
+	mux.Handle("/fever/", a.withJSON(http.HandlerFunc(a.handleFever)))
+	mux.Handle("/fever", a.withJSON(http.HandlerFunc(a.handleFever)))
+
 	mux.Handle("/api/login", a.withJSON(http.HandlerFunc(a.handleUserLogin)))
 	mux.Handle("/api/logout", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleUserLogout)))))
 	mux.Handle("/api/session", a.withJSON(http.HandlerFunc(a.handleUserSession)))
@@ -47,15 +64,31 @@ func (a *API) Routes() http.Handler {
 	mux.Handle("/api/feed/seen", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleMarkSeen)))))
 	mux.Handle("/api/feed/refresh", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleFeedRefresh)))))
 	mux.Handle("/api/articles/action", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleArticleAction)))))
+	mux.Handle("/api/articles/undo", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleUndo)))))
 	mux.Handle("/api/articles/click", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleArticleClick)))))
 	mux.Handle("/api/articles/dontshow", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleDontShow)))))
+	mux.Handle("/api/articles/boost", a.userOnly(a.userCSRF(a.withJSON(http.HandlerFunc(a.handleBoostRule)))))
+	mux.Handle("/api/articles/", a.userOnly(a.withJSON(http.HandlerFunc(a.handleArticleExplain))))
+	mux.Handle("/api/stories/", a.userOnly(a.withJSON(http.HandlerFunc(a.handleStory))))
+	mux.Handle("/api/search", a.userOnly(a.withJSON(http.HandlerFunc(a.handleSearch))))
+	mux.Handle("/api/history", a.userOnly(a.withJSON(http.HandlerFunc(a.handleHistory))))
 
 	mux.Handle("/admin/api/login", a.withJSON(http.HandlerFunc(a.handleAdminLogin)))
 	mux.Handle("/admin/api/logout", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminLogout)))))
 	mux.Handle("/admin/api/session", a.guard.AdminOnly(a.withJSON(http.HandlerFunc(a.handleAdminSession))))
 	mux.Handle("/admin/api/topics", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminTopics)))))
+	mux.Handle("/admin/api/topics/opml", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminTopicsOPML)))))
 	mux.Handle("/admin/api/rules", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminRules)))))
+	mux.Handle("/admin/api/feed-tokens", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminFeedTokens)))))
 	mux.Handle("/admin/api/ingest", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminIngest)))))
+	mux.Handle("/admin/api/ingest/cancel", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminIngestCancel)))))
+	mux.Handle("/admin/api/ingest/jobs", a.guard.AdminOnly(a.withJSON(http.HandlerFunc(a.handleAdminIngestJobs))))
+	mux.Handle("/admin/api/ingest/events", a.guard.AdminOnly(http.HandlerFunc(a.handleAdminIngestEvents)))
+	mux.Handle("/admin/api/instances", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminInstances)))))
+	mux.Handle("/admin/api/instances/probe", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminInstanceProbe)))))
+	mux.Handle("/admin/api/url-rules", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminURLRules)))))
+	mux.Handle("/admin/api/url-rules/migrate", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminURLMigrate)))))
+	mux.Handle("/admin/api/runs", a.guard.AdminOnly(a.withJSON(http.HandlerFunc(a.handleAdminRuns))))
 	mux.Handle("/admin/api/dedupe", a.guard.AdminOnly(a.adminCSRF(a.withJSON(http.HandlerFunc(a.handleAdminDedupe)))))
 	mux.Handle("/admin/api/status", a.guard.AdminOnly(a.withJSON(http.HandlerFunc(a.handleAdminStatus))))
 	return mux
@@ -94,12 +127,23 @@ func (a *API) handleFeed(w http.ResponseWriter, r *http.Request) {
 			limit = n
 		}
 	}
-	items, err := a.store.FetchTopUnread(r.Context(), limit, a.cfg.FeedMinScore)
+	items, err := a.store.FetchTopUnread(r.Context(), limit, a.cfg.FeedMinScore, a.cfg.RecencyHalfLifeHours)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	storyIDs := make([]int64, 0, len(items))
+	for _, it := range items {
+		if it.StoryID > 0 {
+			storyIDs = append(storyIDs, it.StoryID)
+		}
+	}
+	storySizes, err := a.store.StorySizes(r.Context(), storyIDs)
 	if err != nil {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, map[string]any{"items": items})
+	respondJSON(w, http.StatusOK, map[string]any{"items": items, "story_sizes": storySizes})
 }
 
 func (a *API) handleMarkSeen(w http.ResponseWriter, r *http.Request) {
@@ -114,7 +158,7 @@ func (a *API) handleMarkSeen(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := a.store.MarkIDsAsSeen(r.Context(), req.IDs); err != nil {
+	if err := a.store.MarkIDsAsSeen(r.Context(), req.IDs, model.StatusSourceSeen); err != nil {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -122,21 +166,14 @@ func (a *API) handleMarkSeen(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *API) handleFeedRefresh(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodGet:
+		a.respondJob(w, r)
+	case http.MethodPost:
+		a.startIngestJob(w)
+	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-	if err := a.scheduler.RunNow(ctx); err != nil {
-		if errors.Is(err, scheduler.ErrIngestAlreadyRunning) || errors.Is(err, scheduler.ErrIngestCooldown) {
-			respondErr(w, http.StatusConflict, err)
-			return
-		}
-		respondErr(w, http.StatusInternalServerError, err)
-		return
-	}
-	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
 func (a *API) handleArticleAction(w http.ResponseWriter, r *http.Request) {
@@ -155,9 +192,9 @@ func (a *API) handleArticleAction(w http.ResponseWriter, r *http.Request) {
 	var err error
 	switch req.Action {
 	case "up":
-		err = a.store.MarkIDStatus(r.Context(), req.ID, model.StatusUseful, 1.0)
+		err = a.store.MarkIDStatus(r.Context(), req.ID, model.StatusUseful, 1.0, model.StatusSourceUp)
 	case "down", "hide":
-		err = a.store.MarkIDStatus(r.Context(), req.ID, model.StatusHidden, -2.5)
+		err = a.store.MarkIDStatus(r.Context(), req.ID, model.StatusHidden, -2.5, model.StatusSourceHide)
 	default:
 		respondErr(w, http.StatusBadRequest, errors.New("invalid action"))
 		return
@@ -169,6 +206,33 @@ func (a *API) handleArticleAction(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
+func (a *API) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Count int `json:"count"`
+	}
+	if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	if req.Count < 1 || req.Count > 50 {
+		respondErr(w, http.StatusBadRequest, errors.New("count must be between 1 and 50"))
+		return
+	}
+	stats, err := a.store.UndoStatusActions(r.Context(), req.Count)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"ok": true, "undo": stats})
+}
+
 func (a *API) handleArticleClick(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -181,7 +245,7 @@ func (a *API) handleArticleClick(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := a.store.MarkRead(r.Context(), req.ID); err != nil {
+	if err := a.store.MarkRead(r.Context(), req.ID, model.StatusSourceClick); err != nil {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -205,17 +269,102 @@ func (a *API) handleDontShow(w http.ResponseWriter, r *http.Request) {
 	if req.Penalty <= 0 {
 		req.Penalty = 10
 	}
-	if err := a.store.UpsertNegativeRule(r.Context(), model.NegativeRule{Pattern: req.Pattern, Penalty: req.Penalty, Enabled: true}); err != nil {
+	if err := validateRulePattern(req.Pattern); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := a.store.UpsertNegativeRule(r.Context(), model.NegativeRule{Kind: model.RuleKindPenalty, Pattern: req.Pattern, Penalty: req.Penalty, Enabled: true}); err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := a.store.MarkIDStatus(r.Context(), req.ID, model.StatusHidden, -req.Penalty, model.StatusSourceDontShow); err != nil {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
-	if err := a.store.MarkIDStatus(r.Context(), req.ID, model.StatusHidden, -req.Penalty); err != nil {
+	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (a *API) handleBoostRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Pattern string  `json:"pattern"`
+		Weight  float64 `json:"weight"`
+	}
+	if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateRulePattern(req.Pattern); err != nil {
+		respondErr(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Weight <= 0 {
+		req.Weight = a.cfg.BoostRuleDefaultWeight
+	}
+	if err := a.store.UpsertNegativeRule(r.Context(), model.NegativeRule{Kind: model.RuleKindBoost, Pattern: req.Pattern, Penalty: req.Weight, Enabled: true}); err != nil {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
 	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
+// handleArticleExplain serves GET /api/articles/{id}/explain.
+func (a *API) handleArticleExplain(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/articles/")
+	idRaw, sub, _ := strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil || id <= 0 || sub != "explain" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out, err := a.store.ExplainArticleScore(r.Context(), id, 100)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondErr(w, http.StatusNotFound, errors.New("article not found"))
+		return
+	}
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, out)
+}
+
+// handleStory serves GET /api/stories/{id}: the story and all of its articles
+// in any status, including ones hidden as duplicates.
+func (a *API) handleStory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/stories/"), 10, 64)
+	if err != nil || id <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	story, err := a.store.GetStory(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondErr(w, http.StatusNotFound, errors.New("story not found"))
+		return
+	}
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	items, err := a.store.ListStoryArticles(r.Context(), id)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"story": story, "items": items})
+}
+
 func (a *API) handleAdminTopics(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -231,7 +380,7 @@ func (a *API) handleAdminTopics(w http.ResponseWriter, r *http.Request) {
 		}
 		respondJSON(w, http.StatusOK, map[string]any{"items": topics, "topic_stats": stats})
 	case http.MethodPost:
-		var req model.Topic
+		req := model.Topic{HalfLifeHours: model.HalfLifeInherit}
 		if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
 			respondErr(w, http.StatusBadRequest, err)
 			return
@@ -239,6 +388,10 @@ func (a *API) handleAdminTopics(w http.ResponseWriter, r *http.Request) {
 		if req.Weight == 0 {
 			req.Weight = 1
 		}
+		if err := validateTopic(req); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
 		if err := a.store.UpsertTopic(r.Context(), req); err != nil {
 			respondErr(w, http.StatusInternalServerError, err)
 			return
@@ -260,6 +413,121 @@ func (a *API) handleAdminTopics(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (a *API) handleAdminTopicsOPML(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		topics, err := a.store.ListTopics(r.Context())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		body, err := opml.Export(topics, time.Now())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="discover-topics.opml"`)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	case http.MethodPost:
+		maxBody := a.cfg.MaxBodyBytes
+		if maxBody <= 0 {
+			maxBody = 1 << 20
+		}
+		defer r.Body.Close()
+		topics, err := opml.Import(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		imported := 0
+		skipped := make([]string, 0)
+		for _, t := range topics {
+			if err := validateTopic(t); err != nil {
+				skipped = append(skipped, t.Query+": "+err.Error())
+				continue
+			}
+			if err := a.store.UpsertTopic(r.Context(), t); err != nil {
+				respondErr(w, http.StatusInternalServerError, err)
+				return
+			}
+			imported++
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true, "imported": imported, "skipped": skipped})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func validateTopic(t model.Topic) error {
+	kind, err := store.NormalizeTopicKind(t.Kind)
+	if err != nil {
+		return err
+	}
+	q := strings.TrimSpace(t.Query)
+	if q == "" {
+		return errors.New("empty query")
+	}
+	if kind == model.TopicKindFeed {
+		u, err := url.Parse(q)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("feed topic query must be an http(s) feed URL")
+		}
+	}
+	if t.HalfLifeHours != model.HalfLifeInherit && (t.HalfLifeHours < 0 || t.HalfLifeHours > 8760) {
+		return errors.New("half_life_hours must be -1 (inherit) or 0..8760")
+	}
+	if err := validateTopicList("categories", t.Categories); err != nil {
+		return err
+	}
+	if err := validateTopicList("engines", t.Engines); err != nil {
+		return err
+	}
+	if err := validateTopicList("time_ranges", t.TimeRanges); err != nil {
+		return err
+	}
+	for _, r := range t.TimeRanges {
+		switch strings.ToLower(strings.TrimSpace(r)) {
+		case "day", "week", "month", "year", "all":
+		default:
+			return fmt.Errorf("time_ranges: %q is not one of day, week, month, year, all", r)
+		}
+	}
+	if lang := strings.TrimSpace(t.Language); lang != "" && (len(lang) > 16 || !topicTokenRe.MatchString(lang)) {
+		return errors.New("language must be a SearXNG language code such as en or en-US")
+	}
+	if t.Pages < 0 || t.Pages > 5 {
+		return errors.New("pages must be 0..5 (0 = default 2)")
+	}
+	if t.MinIntervalMinutes < 0 || t.MinIntervalMinutes > 7*24*60 {
+		return errors.New("min_interval_minutes must be 0..10080")
+	}
+	return nil
+}
+
+var topicTokenRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]*$`)
+
+func validateTopicList(name string, items []string) error {
+	if len(items) > 10 {
+		return fmt.Errorf("%s: at most 10 entries", name)
+	}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if len(item) > 40 || !topicTokenRe.MatchString(item) {
+			return fmt.Errorf("%s: invalid entry %q", name, item)
+		}
+	}
+	return nil
+}
+
+func validateRulePattern(pattern string) error {
+	if err := matcher.Validate(pattern); err != nil {
+		return fmt.Errorf("invalid rule pattern: %w", err)
+	}
+	return nil
+}
+
 func (a *API) handleAdminRules(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -268,6 +536,11 @@ func (a *API) handleAdminRules(w http.ResponseWriter, r *http.Request) {
 			respondErr(w, http.StatusInternalServerError, err)
 			return
 		}
+		for i := range rules {
+			if err := matcher.Validate(rules[i].Pattern); err != nil {
+				rules[i].Error = err.Error()
+			}
+		}
 		respondJSON(w, http.StatusOK, map[string]any{"items": rules})
 	case http.MethodPost:
 		var req model.NegativeRule
@@ -278,6 +551,14 @@ func (a *API) handleAdminRules(w http.ResponseWriter, r *http.Request) {
 		if req.Penalty == 0 {
 			req.Penalty = 5
 		}
+		if _, err := store.NormalizeRuleKind(req.Kind); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := validateRulePattern(req.Pattern); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
 		if err := a.store.UpsertNegativeRule(r.Context(), req); err != nil {
 			respondErr(w, http.StatusInternalServerError, err)
 			return
@@ -299,22 +580,58 @@ func (a *API) handleAdminRules(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (a *API) handleAdminFeedTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tokens, err := a.store.ListFeedTokens(r.Context())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"items": tokens})
+	case http.MethodPost:
+		var req struct {
+			Label string `json:"label"`
+		}
+		if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		token, item, err := a.store.CreateFeedToken(r.Context(), req.Label)
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		base := requestBaseURL(r)
+		respondJSON(w, http.StatusOK, map[string]any{
+			"ok":       true,
+			"item":     item,
+			"token":    token,
+			"atom_url": base + "/feeds/atom?token=" + url.QueryEscape(token),
+			"rss_url":  base + "/feeds/rss?token=" + url.QueryEscape(token),
+		})
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := a.store.DeleteFeedToken(r.Context(), id); err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func (a *API) handleAdminIngest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-	if err := a.scheduler.RunNow(ctx); err != nil {
-		if errors.Is(err, scheduler.ErrIngestAlreadyRunning) || errors.Is(err, scheduler.ErrIngestCooldown) {
-			respondErr(w, http.StatusConflict, err)
-			return
-		}
-		respondErr(w, http.StatusInternalServerError, err)
-		return
-	}
-	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	a.startIngestJob(w)
 }
 
 func (a *API) handleAdminDedupe(w http.ResponseWriter, r *http.Request) {
@@ -389,6 +706,7 @@ func (a *API) handleAdminLogin(w http.ResponseWriter, r *http.Request) {
 		"ok":                        true,
 		"csrf_token":                csrfToken,
 		"hide_rule_default_penalty": a.cfg.HideRuleDefaultPenalty,
+		"boost_rule_default_weight": a.cfg.BoostRuleDefaultWeight,
 	})
 }
 
@@ -429,6 +747,11 @@ func (a *API) handleAdminStatus(w http.ResponseWriter, r *http.Request) {
 		respondErr(w, http.StatusInternalServerError, err)
 		return
 	}
+	instances, err := a.instanceHealth(r.Context())
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
 	msg, msgAt := "", time.Time{}
 	if a.progress != nil {
 		msg, msgAt = a.progress.LastProgress()
@@ -436,14 +759,38 @@ func (a *API) handleAdminStatus(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]any{
 		"ingest": map[string]any{
 			"state":           a.scheduler.Snapshot(),
+			"schedule":        a.scheduler.Schedule(5),
 			"last_message":    msg,
 			"last_message_at": msgAt,
 		},
 		"counts":              counts,
 		"dedupe_hidden_total": dedupeHiddenTotal,
+		"instances":           instances,
 	})
 }
 
+// instanceHealth lists the health of every enabled instance, including
+// ones never tried yet.
+func (a *API) instanceHealth(ctx context.Context) ([]model.InstanceHealth, error) {
+	instances, err := a.store.ListEnabledSearxInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	health, err := a.store.InstanceHealthMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.InstanceHealth, 0, len(instances))
+	for _, inst := range instances {
+		h, ok := health[inst.URL]
+		if !ok {
+			h.URL = inst.URL
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
 func (a *API) handleAdminSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -463,6 +810,7 @@ func (a *API) handleAdminSession(w http.ResponseWriter, r *http.Request) {
 		"ok":                        true,
 		"csrf_token":                csrfToken,
 		"hide_rule_default_penalty": a.cfg.HideRuleDefaultPenalty,
+		"boost_rule_default_weight": a.cfg.BoostRuleDefaultWeight,
 	})
 }
 
@@ -510,6 +858,7 @@ func (a *API) handleUserLogin(w http.ResponseWriter, r *http.Request) {
 		"ok":                        true,
 		"csrf_token":                csrfToken,
 		"hide_rule_default_penalty": a.cfg.HideRuleDefaultPenalty,
+		"boost_rule_default_weight": a.cfg.BoostRuleDefaultWeight,
 	})
 }
 
@@ -553,6 +902,7 @@ func (a *API) handleUserSession(w http.ResponseWriter, r *http.Request) {
 		"ok":                        true,
 		"csrf_token":                csrfToken,
 		"hide_rule_default_penalty": a.cfg.HideRuleDefaultPenalty,
+		"boost_rule_default_weight": a.cfg.BoostRuleDefaultWeight,
 	})
 }
 
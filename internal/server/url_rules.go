@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+	"discover/internal/scheduler"
+	"discover/internal/store"
+	"discover/internal/urlcanon"
+)
+
+// handleAdminURLRules lists, adds/updates and deletes per-domain URL
+// canonicalization rules. Rules apply to new ingest hits; existing articles
+// follow after a migrate.
+func (a *API) handleAdminURLRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		items, err := a.store.ListURLRules(r.Context())
+		if err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"items": items})
+	case http.MethodPost:
+		var req model.URLRule
+		if err := decodeJSON(r, a.cfg.MaxBodyBytes, &req); err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		rule, err := normalizeURLRule(req)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := a.store.UpsertURLRule(r.Context(), rule); err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			respondErr(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := a.store.DeleteURLRule(r.Context(), id); err != nil {
+			respondErr(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAdminURLMigrate recomputes every article's canonical URL with the
+// current rules and merges articles that now collide. It refuses to start
+// during an ingest run and holds off new runs until it is done.
+func (a *API) handleAdminURLMigrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	rules, err := a.store.ListURLRules(ctx)
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	var stats store.RecanonicalizeStats
+	err = a.scheduler.Exclusive(func() error {
+		var err error
+		stats, err = a.store.RecanonicalizeArticles(ctx, urlcanon.New(rules).Key)
+		return err
+	})
+	if errors.Is(err, scheduler.ErrIngestAlreadyRunning) {
+		respondErr(w, http.StatusConflict, errors.New("an ingest run is in progress; try again when it has finished"))
+		return
+	}
+	if err != nil {
+		respondErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"ok": true, "stats": stats})
+}
+
+func normalizeURLRule(rule model.URLRule) (model.URLRule, error) {
+	rule.Domain = urlcanon.NormalizeDomain(rule.Domain)
+	if rule.Domain == "" || len(rule.Domain) > 253 || strings.ContainsAny(rule.Domain, "/:?#* ") || !strings.Contains(rule.Domain, ".") {
+		return rule, errors.New("domain must be a host name such as example.com")
+	}
+	for _, list := range []*string{&rule.KeepParams, &rule.StripParams} {
+		if len(*list) > 1000 {
+			return rule, errors.New("parameter list too long")
+		}
+		if err := urlcanon.CheckParams(*list); err != nil {
+			return rule, err
+		}
+		*list = strings.Join(urlcanon.SplitParams(*list), ",")
+	}
+	return rule, nil
+}
@@ -1,18 +1,30 @@
 const statusEl = document.getElementById('status');
 const ingestStateEl = document.getElementById('ingestState');
+const ingestLogEl = document.getElementById('ingestLog');
 const countsEl = document.getElementById('counts');
 const secretEl = document.getElementById('secret');
 const runIngestBtn = document.getElementById('runIngest');
 const runDedupeBtn = document.getElementById('runDedupe');
+const cancelIngestBtn = document.getElementById('cancelIngest');
 const loginBtn = document.getElementById('loginBtn');
 const logoutBtn = document.getElementById('logoutBtn');
 const topicsPanel = document.getElementById('topicsPanel');
 const rulesPanel = document.getElementById('rulesPanel');
+const feedTokensPanel = document.getElementById('feedTokensPanel');
+const searxPanel = document.getElementById('searxPanel');
+const urlRulesPanel = document.getElementById('urlRulesPanel');
+const probeResultEl = document.getElementById('probeResult');
 const ingestionPanel = document.getElementById('ingestionPanel');
+const runsPanel = document.getElementById('runsPanel');
+const runDetailEl = document.getElementById('runDetail');
+const instancesPanel = document.getElementById('instancesPanel');
+const instancesEl = document.getElementById('instances');
 const countsPanel = document.getElementById('countsPanel');
 
 let manualIngestInFlight = false;
 let manualDedupeInFlight = false;
+let ingestEvents = null;
+let ingestEventsJob = 0;
 let authenticated = false;
 let csrfToken = '';
 
@@ -68,7 +80,12 @@ function setAuthUI() {
   runDedupeBtn.disabled = !authenticated || manualDedupeInFlight || manualIngestInFlight;
   topicsPanel.hidden = !authenticated;
   rulesPanel.hidden = !authenticated;
+  feedTokensPanel.hidden = !authenticated;
+  searxPanel.hidden = !authenticated;
+  urlRulesPanel.hidden = !authenticated;
   ingestionPanel.hidden = !authenticated;
+  runsPanel.hidden = !authenticated;
+  instancesPanel.hidden = !authenticated;
   countsPanel.hidden = !authenticated;
 }
 
@@ -101,11 +118,22 @@ logoutBtn.onclick = async () => {
   csrfToken = '';
   manualIngestInFlight = false;
   manualDedupeInFlight = false;
+  stopIngestEvents();
+  ingestLogEl.hidden = true;
+  ingestLogEl.textContent = '';
   setAuthUI();
   document.getElementById('topics').innerHTML = '';
   document.getElementById('rules').innerHTML = '';
+  document.getElementById('feedTokens').innerHTML = '';
+  document.getElementById('feedTokenResult').textContent = '';
+  document.getElementById('urlRules').innerHTML = '';
+  document.getElementById('runs').innerHTML = '';
+  document.getElementById('topicHealth').innerHTML = '';
+  runDetailEl.hidden = true;
+  runDetailEl.textContent = '';
   ingestStateEl.textContent = '';
   countsEl.textContent = '';
+  instancesEl.innerHTML = '';
   status('signed out');
 };
 
@@ -116,13 +144,77 @@ async function loadTopics() {
     const s = stats[String(t.id)] || {};
     const unread = Number(s.unread || 0);
     const total = Number(s.total || 0);
-    return `<li>${escHtml(t.query)} (w=${t.weight}, enabled=${t.enabled}, unread=${unread}, total=${total}) <button data-edit-topic="1" data-topic-query="${escAttr(t.query)}" data-topic-weight="${t.weight}" data-topic-enabled="${t.enabled}">edit</button> <button data-del-topic="${t.id}">delete</button></li>`;
+    const kind = t.kind || 'search';
+    const cats = (t.categories || []).join(',');
+    const engines = (t.engines || []).join(',');
+    const ranges = (t.time_ranges || []).join(',');
+    const harvest = kind === 'search' && (cats || engines || t.language || ranges || t.pages > 0)
+      ? `, harvest=${escHtml([cats && `cat:${cats}`, engines && `eng:${engines}`, t.language && `lang:${t.language}`, ranges && `range:${ranges}`, t.pages > 0 && `pages:${t.pages}`].filter(Boolean).join(' '))}`
+      : '';
+    const interval = t.min_interval_minutes > 0 ? `, every>=${t.min_interval_minutes}m, last_fetched=${escHtml(dbTime(t.last_fetched_at))}` : '';
+    return `<li>${escHtml(t.query)} (kind=${escHtml(kind)}, w=${t.weight}${t.half_life_hours > 0 ? `, half-life=${t.half_life_hours}h` : t.half_life_hours === 0 ? ', no decay' : ''}${harvest}${interval}, enabled=${t.enabled}, unread=${unread}, total=${total}) <button data-edit-topic="1" data-topic-kind="${escAttr(kind)}" data-topic-query="${escAttr(t.query)}" data-topic-weight="${t.weight}" data-topic-half-life="${t.half_life_hours ?? -1}" data-topic-enabled="${t.enabled}" data-topic-cats="${escAttr(cats)}" data-topic-engines="${escAttr(engines)}" data-topic-lang="${escAttr(t.language)}" data-topic-ranges="${escAttr(ranges)}" data-topic-pages="${t.pages || 0}" data-topic-interval="${t.min_interval_minutes || 0}">edit</button> <button data-del-topic="${t.id}">delete</button></li>`;
   }).join('');
 }
 
 async function loadRules() {
   const j = await call('/admin/api/rules');
-  document.getElementById('rules').innerHTML = (j.items || []).map(r => `<li>${escHtml(r.pattern)} (-${r.penalty}, enabled=${r.enabled}, applied=${Number(r.applied_count || 0)}) <button data-edit-rule="1" data-rule-pattern="${escAttr(r.pattern)}" data-rule-penalty="${r.penalty}" data-rule-enabled="${r.enabled}">edit</button> <button data-del-rule="${r.id}">delete</button></li>`).join('');
+  document.getElementById('rules').innerHTML = (j.items || []).map(r => `<li>${r.error ? `⚠ invalid, skipped at ingest: ${escHtml(r.error)} — ` : ''}${escHtml(r.pattern)} (${r.kind === 'boost' ? '+' : '-'}${r.penalty}, enabled=${r.enabled}, applied=${Number(r.applied_count || 0)}) <button data-edit-rule="1" data-rule-kind="${escAttr(r.kind || 'penalty')}" data-rule-pattern="${escAttr(r.pattern)}" data-rule-penalty="${r.penalty}" data-rule-enabled="${r.enabled}">edit</button> <button data-del-rule="${r.id}">delete</button></li>`).join('');
+}
+
+async function loadFeedTokens() {
+  const j = await call('/admin/api/feed-tokens');
+  document.getElementById('feedTokens').innerHTML = (j.items || []).map(t => `<li>${escHtml(t.label || '(no label)')} (token=${escHtml(t.prefix)}..., created=${escHtml(t.created_at)}, last_used=${escHtml(t.last_used_at && !String(t.last_used_at).startsWith('0001') ? t.last_used_at : '-')}) <button data-del-feed-token="${t.id}">revoke</button></li>`).join('');
+}
+
+async function loadSearxInstances() {
+  const j = await call('/admin/api/instances');
+  const health = j.health || {};
+  document.getElementById('searxInstances').innerHTML = (j.items || []).map(i => {
+    const h = health[i.url] || {};
+    return `<li>${escHtml(i.url)} (provider=${escHtml(i.provider)}${i.provider === 'searxng' ? `, format=${escHtml(i.response_format || 'auto')}` : ''}${i.has_api_key ? ', api_key=set' : ''}, priority=${i.priority}, delay=${i.delay_seconds}s${i.extra_params ? `, params=${escHtml(i.extra_params)}` : ''}, enabled=${i.enabled}, attempts=${Number(h.attempts || 0)}, success=${pct(h.successes || 0, h.attempts || 0)}) <button data-edit-instance="1" data-instance-provider="${escAttr(i.provider)}" data-instance-url="${escAttr(i.url)}" data-instance-priority="${i.priority}" data-instance-delay="${i.delay_seconds}" data-instance-params="${escAttr(i.extra_params)}" data-instance-enabled="${i.enabled}">edit</button> <button data-probe-instance="${i.id}">probe</button> <button data-del-instance="${i.id}">delete</button></li>`;
+  }).join('');
+}
+
+async function loadURLRules() {
+  const j = await call('/admin/api/url-rules');
+  document.getElementById('urlRules').innerHTML = (j.items || []).map(r => `<li>${escHtml(r.domain)} (keep=${escHtml(r.keep_params || '-')}, strip=${escHtml(r.strip_params || '-')}) <button data-edit-url-rule="1" data-url-rule-domain="${escAttr(r.domain)}" data-url-rule-keep="${escAttr(r.keep_params)}" data-url-rule-strip="${escAttr(r.strip_params)}">edit</button> <button data-del-url-rule="${r.id}">delete</button></li>`).join('');
+}
+
+function showProbe(p) {
+  probeResultEl.hidden = false;
+  probeResultEl.textContent =
+    `${p.url}\n` +
+    `reachable: ${p.reachable}${p.status_code ? ` (HTTP ${p.status_code})` : ''}\n` +
+    `json_enabled: ${p.json_enabled}\n` +
+    (p.format ? `format: ${p.format}\n` : '') +
+    `results: ${p.results}\n` +
+    `latency: ${p.latency_ms} ms` +
+    (p.error ? `\nerror: ${p.error}` : '');
+}
+
+function dbTime(v) {
+  return v && !String(v).startsWith('0001') ? v : '-';
+}
+
+async function loadRuns() {
+  const j = await call('/admin/api/runs');
+  document.getElementById('runs').innerHTML = (j.runs || []).map(r => `<li>#${r.id} ${escHtml(r.source)} ${escHtml(r.status)} (started=${escHtml(dbTime(r.started_at))}, topics=${r.topics}, failed=${r.failed_topics}, fetched=${r.fetched_entries}, new=${r.new_articles}, updated=${r.updated_articles}${r.error ? `, error=${escHtml(r.error)}` : ''}) <button data-show-run="${r.id}">topics</button></li>`).join('');
+  document.getElementById('topicHealth').innerHTML = (j.topics || []).map(t => {
+    const flag = t.runs > 0 && t.empty_streak >= 3 ? '⚠ ' : '';
+    return `<li>${flag}${escHtml(t.query)} (kind=${escHtml(t.kind)}, enabled=${t.enabled}, runs=${t.runs}, last_results=${t.last_results}, empty_streak=${t.empty_streak}, last_nonempty=${escHtml(dbTime(t.last_nonempty_at))}, last_run=${escHtml(dbTime(t.last_run_at))}${t.last_error ? `, last_error=${escHtml(t.last_error)}` : ''})</li>`;
+  }).join('');
+}
+
+async function showRun(id) {
+  const j = await call(`/admin/api/runs?id=${id}`);
+  const r = j.run || {};
+  const lines = (j.topics || []).map(t => `${t.error ? 'ERR ' : t.results === 0 ? 'EMPTY ' : ''}${t.query} [${t.instance || '-'}] results=${t.results} new=${t.new_articles} updated=${t.updated_articles} ${t.duration_ms}ms${t.error ? ` error=${t.error}` : ''}`);
+  runDetailEl.hidden = false;
+  runDetailEl.textContent = `run #${r.id} ${r.source} ${r.status} started=${dbTime(r.started_at)} finished=${dbTime(r.finished_at)}\n${lines.join('\n') || '(no topics recorded)'}`;
+}
+
+function csvList(id) {
+  return document.getElementById(id).value.split(',').map(v => v.trim()).filter(Boolean);
 }
 
 document.getElementById('addTopic').onclick = async () => {
@@ -131,7 +223,22 @@ document.getElementById('addTopic').onclick = async () => {
     return;
   }
   try {
-    await call('/admin/api/topics', { method: 'POST', body: JSON.stringify({ query: document.getElementById('topicQ').value, weight: Number(document.getElementById('topicW').value || 1), enabled: document.getElementById('topicE').checked }) });
+    await call('/admin/api/topics', {
+      method: 'POST',
+      body: JSON.stringify({
+        kind: document.getElementById('topicK').value,
+        query: document.getElementById('topicQ').value,
+        weight: Number(document.getElementById('topicW').value || 1),
+        half_life_hours: document.getElementById('topicH').value === '' ? -1 : Number(document.getElementById('topicH').value),
+        enabled: document.getElementById('topicE').checked,
+        categories: csvList('topicCats'),
+        engines: csvList('topicEngines'),
+        language: document.getElementById('topicLang').value.trim(),
+        time_ranges: csvList('topicRanges'),
+        pages: Number(document.getElementById('topicPages').value || 0),
+        min_interval_minutes: Number(document.getElementById('topicInterval').value || 0),
+      }),
+    });
     await loadTopics();
     status('topic saved');
   } catch (e) {
@@ -139,13 +246,130 @@ document.getElementById('addTopic').onclick = async () => {
   }
 };
 
+document.getElementById('importOpml').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  const file = document.getElementById('opmlFile').files[0];
+  if (!file) {
+    status('choose an OPML file first');
+    return;
+  }
+  try {
+    const body = await file.text();
+    const res = await call('/admin/api/topics/opml', { method: 'POST', headers: { 'Content-Type': 'text/x-opml' }, body });
+    await loadTopics();
+    const skipped = res.skipped || [];
+    status(`OPML import: imported=${Number(res.imported || 0)}, skipped=${skipped.length}${skipped.length ? `\n${skipped.join('\n')}` : ''}`);
+  } catch (e) {
+    status(`OPML import failed: ${e.message}`);
+  }
+};
+
+document.getElementById('addFeedToken').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  try {
+    const res = await call('/admin/api/feed-tokens', { method: 'POST', body: JSON.stringify({ label: document.getElementById('feedTokenLabel').value }) });
+    document.getElementById('feedTokenResult').textContent = `Atom: ${res.atom_url}\nRSS:  ${res.rss_url}\n(copy now; the token is not shown again)`;
+    document.getElementById('feedTokenLabel').value = '';
+    await loadFeedTokens();
+    status('output feed token created');
+  } catch (e) {
+    status(`output feed token create failed: ${e.message}`);
+  }
+};
+
+document.getElementById('addInstance').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  try {
+    await call('/admin/api/instances', {
+      method: 'POST',
+      body: JSON.stringify({
+        url: document.getElementById('instURL').value,
+        provider: document.getElementById('instProvider').value,
+        api_key: document.getElementById('instKey').value,
+        priority: Number(document.getElementById('instPriority').value || 0),
+        delay_seconds: Number(document.getElementById('instDelay').value || 0),
+        extra_params: document.getElementById('instParams').value,
+        enabled: document.getElementById('instE').checked,
+      }),
+    });
+    document.getElementById('instKey').value = '';
+    await loadSearxInstances();
+    await refreshStatus();
+    status('instance saved');
+  } catch (e) {
+    status(`instance save failed: ${e.message}`);
+  }
+};
+
+document.getElementById('addURLRule').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  try {
+    await call('/admin/api/url-rules', {
+      method: 'POST',
+      body: JSON.stringify({
+        domain: document.getElementById('urlRuleDomain').value,
+        keep_params: document.getElementById('urlRuleKeep').value,
+        strip_params: document.getElementById('urlRuleStrip').value,
+      }),
+    });
+    await loadURLRules();
+    status('URL rule saved; run Migrate Articles to apply it to stored articles');
+  } catch (e) {
+    status(`URL rule save failed: ${e.message}`);
+  }
+};
+
+document.getElementById('migrateURLs').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  if (!confirm('Recompute canonical URLs of all stored articles and merge duplicates? Merged articles cannot be split again.')) return;
+  try {
+    status('URL migration running...');
+    const res = await call('/admin/api/url-rules/migrate', { method: 'POST', body: JSON.stringify({}) });
+    const st = res.stats || {};
+    status(`URL migration completed: scanned=${Number(st.scanned || 0)}, rehashed=${Number(st.rehashed || 0)}, merged=${Number(st.merged || 0)}, skipped=${Number(st.skipped || 0)}`);
+    await refreshStatus();
+  } catch (e) {
+    status(`URL migration failed: ${e.message}`);
+  }
+};
+
+document.getElementById('probeInstance').onclick = async () => {
+  if (!authenticated) {
+    status('sign in first');
+    return;
+  }
+  try {
+    status('probing instance...');
+    const res = await call('/admin/api/instances/probe', { method: 'POST', body: JSON.stringify({ url: document.getElementById('instURL').value, provider: document.getElementById('instProvider').value, api_key: document.getElementById('instKey').value, extra_params: document.getElementById('instParams').value }) });
+    showProbe(res.probe || {});
+    status('probe finished');
+  } catch (e) {
+    status(`probe failed: ${e.message}`);
+  }
+};
+
 document.getElementById('addRule').onclick = async () => {
   if (!authenticated) {
     status('sign in first');
     return;
   }
   try {
-    await call('/admin/api/rules', { method: 'POST', body: JSON.stringify({ pattern: document.getElementById('ruleP').value, penalty: Number(document.getElementById('rulePenalty').value || 5), enabled: document.getElementById('ruleE').checked }) });
+    await call('/admin/api/rules', { method: 'POST', body: JSON.stringify({ kind: document.getElementById('ruleK').value, pattern: document.getElementById('ruleP').value, penalty: Number(document.getElementById('rulePenalty').value || 5), enabled: document.getElementById('ruleE').checked }) });
     await loadRules();
     status('rule saved');
   } catch (e) {
@@ -153,6 +377,47 @@ document.getElementById('addRule').onclick = async () => {
   }
 };
 
+function appendIngestLog(line) {
+  const atBottom = ingestLogEl.scrollTop + ingestLogEl.clientHeight >= ingestLogEl.scrollHeight - 4;
+  ingestLogEl.textContent += `${line}\n`;
+  if (atBottom) ingestLogEl.scrollTop = ingestLogEl.scrollHeight;
+}
+
+function stopIngestEvents() {
+  if (ingestEvents) ingestEvents.close();
+  ingestEvents = null;
+}
+
+// watchIngest streams a job's progress lines into the ingestion log.
+function watchIngest(jobId) {
+  if (!jobId || (ingestEvents && ingestEventsJob === jobId)) return;
+  stopIngestEvents();
+  ingestEventsJob = jobId;
+  ingestLogEl.hidden = false;
+  ingestLogEl.textContent = '';
+  const es = new EventSource(`/admin/api/ingest/events?id=${jobId}`);
+  ingestEvents = es;
+  es.addEventListener('job', (e) => {
+    if (ingestLogEl.textContent) return;
+    const j = JSON.parse(e.data);
+    appendIngestLog(`job #${j.id} (${j.source}) started ${j.started_at}`);
+  });
+  es.addEventListener('line', (e) => appendIngestLog(e.data));
+  es.addEventListener('done', (e) => {
+    const j = JSON.parse(e.data);
+    appendIngestLog(`job #${j.id} ${j.state}${j.error ? `: ${j.error}` : ''}`);
+    stopIngestEvents();
+    if (j.state === 'cancelled') {
+      status('ingest cancelled; results stored so far were kept');
+    } else if (manualIngestInFlight) {
+      status(j.state === 'done' ? 'manual ingest completed' : `manual ingest failed: ${j.error || j.state}`);
+    }
+    manualIngestInFlight = false;
+    refreshStatus().catch(() => {});
+    loadRuns().catch(() => {});
+  });
+}
+
 runIngestBtn.onclick = async () => {
   if (manualIngestInFlight || runIngestBtn.disabled) {
     status('manual ingest ignored: already running');
@@ -163,18 +428,30 @@ runIngestBtn.onclick = async () => {
     runIngestBtn.disabled = true;
     runIngestBtn.classList.add('is-busy');
     runIngestBtn.textContent = 'Run Now (Running...)';
-    status('manual ingest requested (running...)');
-    await call('/admin/api/ingest', { method: 'POST', body: JSON.stringify({}) });
-    status('manual ingest completed');
-    await refreshStatus();
+    const res = await call('/admin/api/ingest', { method: 'POST', body: JSON.stringify({}) });
+    const job = res.job || {};
+    status(`manual ingest started (job #${job.id})`);
+    watchIngest(job.id);
+    await refreshStatus().catch(() => {});
   } catch (e) {
+    manualIngestInFlight = false;
     if (String(e.message).includes('just completed')) {
       status(`manual ingest cooldown: ${e.message}`);
     } else {
       status(`manual ingest failed: ${e.message}`);
     }
-  } finally {
-    manualIngestInFlight = false;
+    await refreshStatus().catch(() => {});
+  }
+};
+
+cancelIngestBtn.onclick = async () => {
+  if (!confirm('Cancel the running ingest? Results stored so far are kept.')) return;
+  try {
+    cancelIngestBtn.disabled = true;
+    await call('/admin/api/ingest/cancel', { method: 'POST', body: JSON.stringify({}) });
+    status('ingest cancel requested');
+  } catch (e) {
+    status(`ingest cancel failed: ${e.message}`);
     await refreshStatus().catch(() => {});
   }
 };
@@ -204,19 +481,82 @@ runDedupeBtn.onclick = async () => {
 
 document.body.addEventListener('click', async (e) => {
   if (e.target.matches('[data-edit-topic]')) {
+    document.getElementById('topicK').value = e.target.dataset.topicKind || 'search';
     document.getElementById('topicQ').value = e.target.dataset.topicQuery || '';
     document.getElementById('topicW').value = e.target.dataset.topicWeight || '1';
+    document.getElementById('topicH').value = Number(e.target.dataset.topicHalfLife) >= 0 ? e.target.dataset.topicHalfLife : '';
     document.getElementById('topicE').checked = String(e.target.dataset.topicEnabled) === 'true';
+    document.getElementById('topicCats').value = e.target.dataset.topicCats || '';
+    document.getElementById('topicEngines').value = e.target.dataset.topicEngines || '';
+    document.getElementById('topicLang').value = e.target.dataset.topicLang || '';
+    document.getElementById('topicRanges').value = e.target.dataset.topicRanges || '';
+    document.getElementById('topicPages').value = Number(e.target.dataset.topicPages || 0) > 0 ? e.target.dataset.topicPages : '';
+    document.getElementById('topicInterval').value = Number(e.target.dataset.topicInterval || 0) > 0 ? e.target.dataset.topicInterval : '';
     document.getElementById('topicQ').focus();
     status('topic loaded into editor');
   }
   if (e.target.matches('[data-edit-rule]')) {
+    document.getElementById('ruleK').value = e.target.dataset.ruleKind || 'penalty';
     document.getElementById('ruleP').value = e.target.dataset.rulePattern || '';
     document.getElementById('rulePenalty').value = e.target.dataset.rulePenalty || '5';
     document.getElementById('ruleE').checked = String(e.target.dataset.ruleEnabled) === 'true';
     document.getElementById('ruleP').focus();
     status('rule loaded into editor');
   }
+  if (e.target.matches('[data-edit-instance]')) {
+    document.getElementById('instProvider').value = e.target.dataset.instanceProvider || 'searxng';
+    document.getElementById('instURL').value = e.target.dataset.instanceUrl || '';
+    document.getElementById('instKey').value = '';
+    document.getElementById('instPriority').value = Number(e.target.dataset.instancePriority || 0) !== 0 ? e.target.dataset.instancePriority : '';
+    document.getElementById('instDelay').value = Number(e.target.dataset.instanceDelay || 0) > 0 ? e.target.dataset.instanceDelay : '';
+    document.getElementById('instParams').value = e.target.dataset.instanceParams || '';
+    document.getElementById('instE').checked = String(e.target.dataset.instanceEnabled) === 'true';
+    document.getElementById('instURL').focus();
+    status('instance loaded into editor');
+  }
+  if (e.target.matches('[data-edit-url-rule]')) {
+    document.getElementById('urlRuleDomain').value = e.target.dataset.urlRuleDomain || '';
+    document.getElementById('urlRuleKeep').value = e.target.dataset.urlRuleKeep || '';
+    document.getElementById('urlRuleStrip').value = e.target.dataset.urlRuleStrip || '';
+    document.getElementById('urlRuleDomain').focus();
+    status('URL rule loaded into editor');
+  }
+  if (e.target.matches('[data-del-url-rule]')) {
+    try {
+      await call(`/admin/api/url-rules?id=${e.target.dataset.delUrlRule}`, { method: 'DELETE' });
+      await loadURLRules();
+      status('URL rule deleted');
+    } catch (err) {
+      status(`URL rule delete failed: ${err.message}`);
+    }
+  }
+  if (e.target.matches('[data-probe-instance]')) {
+    try {
+      status('probing instance...');
+      const res = await call('/admin/api/instances/probe', { method: 'POST', body: JSON.stringify({ id: Number(e.target.dataset.probeInstance) }) });
+      showProbe(res.probe || {});
+      status('probe finished');
+    } catch (err) {
+      status(`probe failed: ${err.message}`);
+    }
+  }
+  if (e.target.matches('[data-del-instance]')) {
+    try {
+      await call(`/admin/api/instances?id=${e.target.dataset.delInstance}`, { method: 'DELETE' });
+      await loadSearxInstances();
+      await refreshStatus();
+      status('instance deleted');
+    } catch (err) {
+      status(`instance delete failed: ${err.message}`);
+    }
+  }
+  if (e.target.matches('[data-show-run]')) {
+    try {
+      await showRun(e.target.dataset.showRun);
+    } catch (err) {
+      status(`run load failed: ${err.message}`);
+    }
+  }
   if (e.target.matches('[data-del-topic]')) {
     try {
       await call(`/admin/api/topics?id=${e.target.dataset.delTopic}`, { method: 'DELETE' });
@@ -226,6 +566,15 @@ document.body.addEventListener('click', async (e) => {
       status(`topic delete failed: ${err.message}`);
     }
   }
+  if (e.target.matches('[data-del-feed-token]')) {
+    try {
+      await call(`/admin/api/feed-tokens?id=${e.target.dataset.delFeedToken}`, { method: 'DELETE' });
+      await loadFeedTokens();
+      status('output feed token revoked');
+    } catch (err) {
+      status(`output feed token revoke failed: ${err.message}`);
+    }
+  }
   if (e.target.matches('[data-del-rule]')) {
     try {
       await call(`/admin/api/rules?id=${e.target.dataset.delRule}`, { method: 'DELETE' });
@@ -237,17 +586,33 @@ document.body.addEventListener('click', async (e) => {
   }
 });
 
+function pct(n, d) {
+  return d > 0 ? `${Math.round((100 * n) / d)}%` : '-';
+}
+
+function renderInstances(items) {
+  const now = Date.now();
+  instancesEl.innerHTML = (items || []).map(h => {
+    const blocked = dbTime(h.blocked_until) !== '-' && new Date(h.blocked_until).getTime() > now;
+    const state = blocked ? `backing off until ${new Date(h.blocked_until).toLocaleTimeString()}` : (h.attempts > 0 ? 'ok' : 'unused');
+    return `<tr class="${blocked ? 'is-blocked' : ''}"><td>${escHtml(h.url)}</td><td>${h.attempts}</td><td>${pct(h.successes, h.attempts)}</td><td>${pct(h.empty_results, h.successes)}</td><td>${h.attempts > 0 ? `${Math.round(h.avg_latency_ms)} ms` : '-'}</td><td>${h.consecutive_failures}</td><td>${escHtml(state)}</td><td>${escHtml(dbTime(h.last_success_at))}</td><td>${h.last_error ? `${escHtml(h.last_error)} (${escHtml(dbTime(h.last_error_at))})` : '-'}</td></tr>`;
+  }).join('');
+}
+
 async function refreshStatus() {
   if (!authenticated) return;
   try {
     const j = await call('/admin/api/status');
     const ingest = j.ingest || {};
     const ingestState = ingest.state || {};
+    const schedule = ingest.schedule || {};
     const counts = j.counts || {};
     const running = manualIngestInFlight || Boolean(ingestState.running);
+    if (ingestState.running) watchIngest(ingestState.job_id);
     runIngestBtn.disabled = !authenticated || running;
     runIngestBtn.classList.toggle('is-busy', running);
     runIngestBtn.textContent = running ? 'Run Now (Running...)' : 'Run Now';
+    cancelIngestBtn.disabled = !authenticated || !ingestState.running;
     runDedupeBtn.disabled = !authenticated || running || manualDedupeInFlight;
     runDedupeBtn.classList.toggle('is-busy', manualDedupeInFlight);
     runDedupeBtn.textContent = manualDedupeInFlight ? 'Run Retroactive Dedupe (Running...)' : 'Run Retroactive Dedupe';
@@ -258,8 +623,12 @@ async function refreshStatus() {
       `last_completed_at: ${ingestState.last_completed_at || '-'}\n` +
       `last_duration_ms: ${ingestState.last_duration_ms || 0}\n` +
       `last_error: ${ingestState.last_error || '-'}\n` +
+      `last_cancelled: ${Boolean(ingestState.last_cancelled)}\n` +
       `last_message: ${ingest.last_message || '-'}\n` +
-      `last_message_at: ${ingest.last_message_at || '-'}`;
+      `last_message_at: ${ingest.last_message_at || '-'}\n` +
+      `schedule: ${schedule.plan || '-'}\n` +
+      `next_runs: ${(schedule.next_runs || []).map(t => new Date(t).toLocaleString()).join(', ') || '-'}`;
+    renderInstances(j.instances);
     countsEl.textContent =
       `unread: ${counts.unread || 0}\n` +
       `seen: ${counts.seen || 0}\n` +
@@ -271,6 +640,7 @@ async function refreshStatus() {
     if (e.status === 401 || e.status === 403) {
       authenticated = false;
       manualIngestInFlight = false;
+      stopIngestEvents();
       manualDedupeInFlight = false;
       setAuthUI();
       status('session expired; sign in again');
@@ -284,6 +654,10 @@ async function bootstrapAfterAuth() {
   try {
     await loadTopics();
     await loadRules();
+    await loadFeedTokens();
+    await loadSearxInstances();
+    await loadURLRules();
+    await loadRuns();
     await refreshStatus();
   } catch (e) {
     status(e.message);
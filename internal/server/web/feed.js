@@ -2,15 +2,28 @@ let currentIds = [];
 let authenticated = false;
 let csrfToken = '';
 let defaultHidePenalty = 10;
+let defaultBoostWeight = 3;
+let storySizes = {};
 
 const feed = document.getElementById('feed');
 const nextBtn = document.getElementById('nextBtn');
+const undoBtn = document.getElementById('undoBtn');
 const statusEl = document.getElementById('status');
 const userAuthTitle = document.getElementById('userAuthTitle');
 const userNameEl = document.getElementById('userName');
 const userSecretEl = document.getElementById('userSecret');
 const userLoginBtn = document.getElementById('userLoginBtn');
 const userLogoutBtn = document.getElementById('userLogoutBtn');
+const searchPanel = document.getElementById('searchPanel');
+const searchQ = document.getElementById('searchQ');
+const resultsEl = document.getElementById('results');
+const resultsTitle = document.getElementById('resultsTitle');
+const resultsList = document.getElementById('resultsList');
+const resultsMore = document.getElementById('resultsMore');
+const tabsEl = document.getElementById('tabs');
+const tabButtons = tabsEl.querySelectorAll('[data-tab]');
+let resultsNext = null;
+let resultsMode = 'feed';
 
 async function api(url, opts = {}) {
   const headers = { ...(opts.headers || {}) };
@@ -36,6 +49,20 @@ function esc(s) {
   return String(s || '').replace(/[&<>"']/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[c]));
 }
 
+// phrasePattern turns a title into a rule phrase, so quotes, dashes, OR and
+// parentheses in it are matched as text instead of rule syntax.
+function phrasePattern(title) {
+  const t = String(title || '').replace(/"/g, ' ').replace(/\s+/g, ' ').trim();
+  return t ? `"${t}"` : '';
+}
+
+// domainPattern scopes a bare hostname to the article domain.
+function domainPattern(input) {
+  const v = String(input || '').trim();
+  if (/^[a-z]+:/i.test(v)) return v;
+  return `domain:${v.replace(/^www\./i, '')}`;
+}
+
 function publishedLabel(v) {
   if (!v) return '';
   const d = new Date(v);
@@ -60,38 +87,200 @@ function setAuthUI() {
   userNameEl.disabled = authenticated;
   userSecretEl.disabled = authenticated;
   nextBtn.disabled = !authenticated;
+  undoBtn.hidden = !authenticated;
+  searchPanel.hidden = !authenticated;
+  tabsEl.hidden = !authenticated;
   if (!authenticated) {
     feed.innerHTML = '';
     currentIds = [];
+    closeResults();
   }
 }
 
 function card(item) {
+  const storySize = Number(storySizes[item.story_id] || 0);
+  const coverage = storySize > 1 ? `<button class="coverage-btn" data-coverage="${item.story_id}">📰 ${storySize} sources</button>` : '';
   const img = item.thumbnail_url ? `<img class="thumb" src="${esc(item.thumbnail_url)}" alt="">` : '';
   const pub = publishedLabel(item.published_at);
   const pubPart = pub ? ` | ${esc(pub)}` : '';
-  return `<article class="card" data-id="${item.id}">
+  const decayed = Number(item.decayed_score);
+  const decayPart = Number.isFinite(decayed) && Math.abs(decayed - Number(item.score)) >= 0.005 ? ` → ${decayed.toFixed(2)}` : '';
+  return `<article class="card${coverage ? ' has-coverage' : ''}" data-id="${item.id}">
     ${img}
     <a class="card-link" href="${esc(item.url)}" target="_blank" rel="noopener" data-click="1">
       <div class="card-main">
         <h3 class="card-title">${esc(item.title)}</h3>
-        <div class="card-source">${esc(item.source_domain || 'unknown')} | score ${Number(item.score).toFixed(2)}${pubPart}</div>
+        <div class="card-source">${esc(item.site_name || item.source_domain || 'unknown')} | score ${Number(item.score).toFixed(2)}${decayPart}${pubPart}</div>
       </div>
     </a>
+    ${coverage}
     <div class="menu"><button data-menu="1">⋯</button><div class="menu-panel">
       <button data-action="up">👍 Useful</button>
       <button data-action="down">👎 Hide</button>
       <button data-action="dont" class="danger">🚫 Hide This</button>
       <button data-action="domain" class="danger">🌐 Hide Domain</button>
+      <button data-boost="keyword">⭐ More Like This</button>
+      <button data-boost="domain">⭐ More From Domain</button>
+      <button data-explain="1">🔎 Why this score</button>
     </div></div>
   </article>`;
 }
 
+function scoreLabel(c) {
+  const d = esc(c.detail);
+  switch (c.component) {
+    case 'base': return 'Base per hit';
+    case 'topic_weight': return `Topic weight: ${d}`;
+    case 'engines': return 'Engine agreement';
+    case 'searx_score': return 'SearXNG score';
+    case 'term_boost': return `Query terms in text: ${d}`;
+    case 'rule_penalty': return `Rule: ${d}`;
+    case 'rule_boost': return `Boost: ${d}`;
+    case 'rule_retroactive': return `Rule (retroactive): ${d}`;
+    case 'floor': return 'Floor at -10';
+    case 'feedback': return `Feedback: ${d}`;
+    default: return esc(c.component);
+  }
+}
+
+function explainHTML(data) {
+  const rows = (data.components || []).map((c) => {
+    const times = c.count > 1 ? ` ×${c.count}` : '';
+    return `<li><span>${scoreLabel(c)}${times}</span><span>${Number(c.delta).toFixed(2)}</span></li>`;
+  });
+  if (Math.abs(Number(data.unexplained || 0)) >= 0.005) {
+    rows.push(`<li><span>Before score history</span><span>${Number(data.unexplained).toFixed(2)}</span></li>`);
+  }
+  const total = Number(data.article?.score || 0).toFixed(2);
+  return `<ul>${rows.join('')}<li class="explain-total"><span>Total</span><span>${total}</span></li></ul>`;
+}
+
+async function toggleExplain(cardEl, id) {
+  const existing = cardEl.querySelector('.explain');
+  if (existing) {
+    existing.remove();
+    cardEl.classList.toggle('expanded', !!cardEl.querySelector('.card-panel'));
+    return;
+  }
+  const data = await api(`/api/articles/${id}/explain`);
+  const box = document.createElement('div');
+  box.className = 'card-panel explain';
+  box.innerHTML = explainHTML(data);
+  cardEl.appendChild(box);
+  cardEl.classList.add('expanded');
+}
+
+function coverageHTML(data) {
+  const rows = (data.items || []).map((it) => {
+    const pub = publishedLabel(it.published_at);
+    const meta = [it.site_name || it.source_domain || 'unknown', pub, it.status].filter(Boolean).map(esc).join(' | ');
+    return `<li><a href="${esc(it.url)}" target="_blank" rel="noopener" data-coverage-click="${it.id}">${esc(it.title)}</a><small>${meta}</small></li>`;
+  });
+  return `<ul>${rows.join('')}</ul>`;
+}
+
+async function toggleCoverage(cardEl, storyID) {
+  const existing = cardEl.querySelector('.coverage');
+  if (existing) {
+    existing.remove();
+    cardEl.classList.toggle('expanded', !!cardEl.querySelector('.card-panel'));
+    return;
+  }
+  const data = await api(`/api/stories/${storyID}`);
+  const box = document.createElement('div');
+  box.className = 'card-panel coverage';
+  box.innerHTML = coverageHTML(data);
+  cardEl.appendChild(box);
+  cardEl.classList.add('expanded');
+}
+
+function resultItem(item) {
+  const pub = publishedLabel(item.published_at);
+  const changed = resultsMode === 'search' ? '' : publishedLabel(item.status_changed_at);
+  const status = changed ? `${item.status} ${changed}` : item.status;
+  const meta = [item.site_name || item.source_domain || 'unknown', pub, status, `score ${Number(item.score).toFixed(2)}`].filter(Boolean).map(esc).join(' | ');
+  return `<li><a href="${esc(item.url)}" target="_blank" rel="noopener">${esc(item.title)}</a><small>${meta}</small></li>`;
+}
+
+function showResults(mode, title) {
+  resultsMode = mode;
+  for (const b of tabButtons) b.classList.toggle('active', b.dataset.tab === mode);
+  resultsTitle.textContent = title;
+  resultsList.innerHTML = '';
+  resultsEl.hidden = false;
+  feed.hidden = true;
+  nextBtn.hidden = true;
+  undoBtn.hidden = true;
+}
+
+function closeResults() {
+  resultsEl.hidden = true;
+  resultsList.innerHTML = '';
+  resultsNext = null;
+  resultsMode = 'feed';
+  for (const b of tabButtons) b.classList.toggle('active', b.dataset.tab === 'feed');
+  feed.hidden = false;
+  nextBtn.hidden = false;
+  undoBtn.hidden = !authenticated;
+}
+
+async function loadResults(url) {
+  const data = await api(url);
+  const items = data.items || [];
+  resultsList.insertAdjacentHTML('beforeend', items.map(resultItem).join(''));
+  resultsNext = data.next_offset ?? data.next_cursor ?? null;
+  resultsMore.hidden = resultsNext == null;
+  return items.length;
+}
+
+function searchURL(offset) {
+  const params = new URLSearchParams({ q: searchQ.value.trim(), sort: document.getElementById('searchSort').value });
+  const status = document.getElementById('searchStatus').value;
+  if (status) params.set('status', status);
+  if (offset) params.set('offset', String(offset));
+  return `/api/search?${params}`;
+}
+
+async function runSearch() {
+  const q = searchQ.value.trim();
+  if (!q) return;
+  try {
+    showResults('search', `Search: ${q}`);
+    resultsMore.onclick = () => loadResults(searchURL(resultsNext)).catch((e) => {
+      statusEl.textContent = `${new Date().toISOString()} search failed: ${e.message}`;
+    });
+    const n = await loadResults(searchURL(0));
+    statusEl.textContent = `${new Date().toISOString()} search returned ${n}${resultsNext != null ? '+' : ''} result(s)`;
+  } catch (e) {
+    statusEl.textContent = `${new Date().toISOString()} search failed: ${e.message}`;
+  }
+}
+
+function historyURL(status, cursor) {
+  const params = new URLSearchParams({ status });
+  if (cursor) params.set('cursor', cursor);
+  return `/api/history?${params}`;
+}
+
+async function openHistory(status, title) {
+  try {
+    showResults(status, title);
+    resultsMore.onclick = () => loadResults(historyURL(status, resultsNext)).catch((e) => {
+      statusEl.textContent = `${new Date().toISOString()} history failed: ${e.message}`;
+    });
+    const n = await loadResults(historyURL(status, ''));
+    statusEl.textContent = `${new Date().toISOString()} loaded ${n} ${status} article(s)`;
+  } catch (e) {
+    statusEl.textContent = `${new Date().toISOString()} history failed: ${e.message}`;
+  }
+}
+
 async function loadFeed() {
   if (!authenticated) return 0;
   try {
     const data = await api('/api/feed');
     const items = data.items || [];
+    storySizes = data.story_sizes || {};
     currentIds = items.map(i => i.id);
     feed.innerHTML = items.map(card).join('');
     statusEl.textContent = `${new Date().toISOString()} loaded ${items.length} cards`;
@@ -119,6 +308,7 @@ userLoginBtn.addEventListener('click', async () => {
     const j = await api('/api/login', { method: 'POST', body: JSON.stringify({ username, secret }) });
     csrfToken = j.csrf_token || '';
     defaultHidePenalty = Number(j.hide_rule_default_penalty || 10);
+    defaultBoostWeight = Number(j.boost_rule_default_weight || 3);
     authenticated = true;
     userSecretEl.value = '';
     setAuthUI();
@@ -129,6 +319,18 @@ userLoginBtn.addEventListener('click', async () => {
   }
 });
 
+document.getElementById('searchBtn').addEventListener('click', runSearch);
+searchQ.addEventListener('keydown', (e) => {
+  if (e.key === 'Enter') runSearch();
+});
+document.getElementById('resultsClose').addEventListener('click', closeResults);
+for (const b of tabButtons) {
+  b.addEventListener('click', () => {
+    if (b.dataset.tab === 'feed') closeResults();
+    else openHistory(b.dataset.tab, b.textContent);
+  });
+}
+
 userLogoutBtn.addEventListener('click', async () => {
   try {
     await api('/api/logout', { method: 'POST', body: JSON.stringify({}) });
@@ -141,6 +343,30 @@ userLogoutBtn.addEventListener('click', async () => {
   statusEl.textContent = `${new Date().toISOString()} signed out`;
 });
 
+undoBtn.addEventListener('click', async () => {
+  if (!authenticated) return;
+  try {
+    const j = await api('/api/articles/undo', { method: 'POST', body: JSON.stringify({ count: 1 }) });
+    await loadFeed();
+    const u = j.undo || {};
+    statusEl.textContent = u.actions
+      ? `${new Date().toISOString()} undid last action (${u.reverted} article(s) restored${u.skipped ? `, ${u.skipped} changed since` : ''})`
+      : `${new Date().toISOString()} nothing to undo`;
+  } catch (e) {
+    statusEl.textContent = `${new Date().toISOString()} undo failed: ${e.message}`;
+  }
+});
+
+// waitForIngest polls a background ingest job until it finishes.
+async function waitForIngest(id) {
+  for (;;) {
+    await new Promise(r => setTimeout(r, 2000));
+    const j = await api(`/api/feed/refresh?id=${id}`);
+    if (j.job.state !== 'running') return j.job;
+    statusEl.textContent = `${new Date().toISOString()} ingest refresh running (${j.job.line_count} step(s) logged)...`;
+  }
+}
+
 nextBtn.addEventListener('click', async () => {
   if (!authenticated) return;
   try {
@@ -149,8 +375,11 @@ nextBtn.addEventListener('click', async () => {
     if (count === 0) {
       statusEl.textContent = `${new Date().toISOString()} no cards left; trying ingest refresh...`;
       try {
-        await api('/api/feed/refresh', { method: 'POST', body: JSON.stringify({}) });
-        statusEl.textContent = `${new Date().toISOString()} ingest refresh completed; loading feed`;
+        const started = await api('/api/feed/refresh', { method: 'POST', body: JSON.stringify({}) });
+        const job = await waitForIngest(started.job.id);
+        statusEl.textContent = job.state === 'done'
+          ? `${new Date().toISOString()} ingest refresh completed; loading feed`
+          : `${new Date().toISOString()} ingest refresh failed: ${job.error || job.state}`;
       } catch (refreshErr) {
         statusEl.textContent = `${new Date().toISOString()} ingest refresh skipped: ${refreshErr.message}`;
       }
@@ -181,12 +410,67 @@ feed.addEventListener('click', async (e) => {
     return;
   }
 
+  if (e.target.matches('[data-coverage]')) {
+    try {
+      await toggleCoverage(cardEl, Number(e.target.dataset.coverage));
+    } catch (err) {
+      statusEl.textContent = `${new Date().toISOString()} coverage load failed: ${err.message}`;
+    }
+    return;
+  }
+
+  if (e.target.closest('[data-coverage-click]')) {
+    try {
+      await api('/api/articles/click', { method: 'POST', body: JSON.stringify({ id: Number(e.target.closest('[data-coverage-click]').dataset.coverageClick) }) });
+    } catch (err) {
+      statusEl.textContent = `${new Date().toISOString()} click tracking failed: ${err.message}`;
+    }
+    return;
+  }
+
+  if (e.target.matches('[data-boost]')) {
+    cardEl.querySelector('.menu').classList.remove('open');
+    try {
+      const byDomain = e.target.dataset.boost === 'domain';
+      let suggested = phrasePattern(cardEl.querySelector('.card-title')?.textContent || '');
+      let label = 'Keyword to boost:';
+      if (byDomain) {
+        label = 'Domain to boost:';
+        try {
+          suggested = new URL(cardEl.querySelector('.card-link')?.href || '').hostname || '';
+        } catch (_) {
+          suggested = '';
+        }
+      }
+      const input = prompt(label, suggested);
+      if (!input) return;
+      const pattern = byDomain ? domainPattern(input) : input;
+      const weight = Number(prompt('Boost weight:', String(defaultBoostWeight)));
+      if (!Number.isFinite(weight) || weight <= 0) return;
+      await api('/api/articles/boost', { method: 'POST', body: JSON.stringify({ pattern, weight }) });
+      statusEl.textContent = `${new Date().toISOString()} boost rule saved`;
+    } catch (err) {
+      statusEl.textContent = `${new Date().toISOString()} boost failed: ${err.message}`;
+    }
+    return;
+  }
+
+  if (e.target.matches('[data-explain]')) {
+    cardEl.querySelector('.menu').classList.remove('open');
+    try {
+      await toggleExplain(cardEl, id);
+    } catch (err) {
+      statusEl.textContent = `${new Date().toISOString()} explain failed: ${err.message}`;
+    }
+    return;
+  }
+
   if (e.target.matches('[data-action]')) {
     try {
       const action = e.target.dataset.action;
       if (action === 'dont') {
-        const suggested = (cardEl.querySelector('.card-title')?.textContent || '').trim();
-        const pattern = prompt('Pattern to hide (text/domain):', suggested);
+        const suggested = phrasePattern(cardEl.querySelector('.card-title')?.textContent || '');
+        const pattern = prompt('Pattern to hide (rule syntax):', suggested);
         if (!pattern) return;
         const penaltyIn = prompt('Penalty weight:', String(defaultHidePenalty));
         const penalty = Number(penaltyIn);
@@ -200,8 +484,9 @@ feed.addEventListener('click', async (e) => {
         } catch (_) {
           suggestedDomain = '';
         }
-        const pattern = prompt('Domain to hide:', suggestedDomain);
-        if (!pattern) return;
+        const domain = prompt('Domain to hide:', suggestedDomain);
+        if (!domain) return;
+        const pattern = domainPattern(domain);
         const penaltyIn = prompt('Penalty weight:', String(defaultHidePenalty));
         const penalty = Number(penaltyIn);
         if (!Number.isFinite(penalty) || penalty <= 0) return;
@@ -240,6 +525,7 @@ statusEl.textContent = `${new Date().toISOString()} checking session...`;
     const j = await api('/api/session');
     csrfToken = j.csrf_token || '';
     defaultHidePenalty = Number(j.hide_rule_default_penalty || 10);
+    defaultBoostWeight = Number(j.boost_rule_default_weight || 3);
     authenticated = true;
     setAuthUI();
     await loadFeed();
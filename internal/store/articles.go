@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"discover/internal/model"
+)
+
+const articleColumns = `id, url, normalized_url, url_hash, title, content, thumbnail_url,
+	source_domain, COALESCE(published_at, ingested_at), ingested_at,
+	status, COALESCE(status_changed_at, updated_at), score, hit_count, engine_count, searx_score, COALESCE(story_id, 0), site_name`
+
+// scanArticle scans articleColumns followed by any extra selected columns.
+func scanArticle(rows *sql.Rows, extra ...any) (model.Article, error) {
+	var a model.Article
+	var status string
+	var publishedRaw any
+	var ingestedRaw any
+	var changedRaw any
+	dest := []any{&a.ID, &a.URL, &a.NormalizedURL, &a.URLHash, &a.Title, &a.Content, &a.ThumbnailURL,
+		&a.SourceDomain, &publishedRaw, &ingestedRaw, &status, &changedRaw, &a.Score, &a.HitCount, &a.EngineCount, &a.SearxScore, &a.StoryID, &a.SiteName}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return model.Article{}, err
+	}
+	a.PublishedAt = parseDBTime(publishedRaw)
+	a.IngestedAt = parseDBTime(ingestedRaw)
+	a.Status = model.ArticleStatus(status)
+	a.StatusChangedAt = parseDBTime(changedRaw)
+	a.DecayedScore = a.Score
+	return a, nil
+}
+
+func (s *Store) queryArticles(ctx context.Context, query string, args ...any) ([]model.Article, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.Article, 0)
+	for rows.Next() {
+		a, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetArticle(ctx context.Context, id int64) (model.Article, error) {
+	items, err := s.ListArticlesByIDs(ctx, []int64{id})
+	if err != nil {
+		return model.Article{}, err
+	}
+	if len(items) == 0 {
+		return model.Article{}, sql.ErrNoRows
+	}
+	return items[0], nil
+}
+
+func (s *Store) ListArticlesByIDs(ctx context.Context, ids []int64) ([]model.Article, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	q, args := inClause(ids)
+	return s.queryArticles(ctx, `SELECT `+articleColumns+` FROM articles WHERE id IN (`+q+`) ORDER BY id`, args...)
+}
+
+// ListSyncArticles pages through articles visible to sync clients (everything
+// except hidden) in id order. sinceID and maxID are exclusive bounds; zero
+// disables a bound. With only maxID set, the newest rows below it are returned.
+func (s *Store) ListSyncArticles(ctx context.Context, sinceID, maxID int64, limit int) ([]model.Article, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if maxID > 0 && sinceID <= 0 {
+		return s.queryArticles(ctx, `
+			SELECT * FROM (
+				SELECT `+articleColumns+` FROM articles
+				WHERE status<>'hidden' AND id < ?
+				ORDER BY id DESC
+				LIMIT ?
+			) ORDER BY id
+		`, maxID, limit)
+	}
+	return s.queryArticles(ctx, `
+		SELECT `+articleColumns+` FROM articles
+		WHERE status<>'hidden' AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`, sinceID, limit)
+}
+
+func (s *Store) CountSyncArticles(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM articles WHERE status<>'hidden'`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) ArticleIDsByStatus(ctx context.Context, status model.ArticleStatus) ([]int64, error) {
+	return s.queryIDs(ctx, `SELECT id FROM articles WHERE status=? ORDER BY id`, string(status))
+}
+
+// UnreadIDsForTopic lists unread article ids ingested before the given time.
+// A zero topicID matches every topic.
+func (s *Store) UnreadIDsForTopic(ctx context.Context, topicID int64, before time.Time) ([]int64, error) {
+	if before.IsZero() {
+		before = time.Now()
+	}
+	if topicID <= 0 {
+		return s.queryIDs(ctx, `SELECT id FROM articles WHERE status='unread' AND ingested_at < ?`, before.UTC())
+	}
+	return s.queryIDs(ctx, `
+		SELECT a.id
+		FROM articles a
+		JOIN article_topics at ON at.article_id = a.id
+		WHERE a.status='unread' AND at.topic_id=? AND a.ingested_at < ?
+	`, topicID, before.UTC())
+}
+
+// PrimaryTopicIDs maps each article to the lowest topic id that found it.
+func (s *Store) PrimaryTopicIDs(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	out := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	q, args := inClause(ids)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT article_id, MIN(topic_id)
+		FROM article_topics
+		WHERE article_id IN (`+q+`)
+		GROUP BY article_id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var articleID, topicID int64
+		if err := rows.Scan(&articleID, &topicID); err != nil {
+			return nil, err
+		}
+		out[articleID] = topicID
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) queryIDs(ctx context.Context, query string, args ...any) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"discover/internal/model"
+	"discover/internal/urlcanon"
+)
+
+// ErrCrossSiteCanonical is returned by RekeyArticle for a canonical URL on
+// another site than the article (see urlcanon.SameSite).
+var ErrCrossSiteCanonical = errors.New("canonical URL is on another site")
+
+// ListCanonicalCandidates returns unread articles whose page has not been
+// checked for a canonical link yet, newest first.
+func (s *Store) ListCanonicalCandidates(ctx context.Context, limit int) ([]model.Article, error) {
+	return s.queryArticles(ctx, `
+		SELECT `+articleColumns+`
+		FROM articles
+		WHERE canonical_checked_at IS NULL AND status='unread'
+		ORDER BY ingested_at DESC, score DESC, id DESC
+		LIMIT ?
+	`, limit)
+}
+
+func (s *Store) MarkCanonicalChecked(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE articles SET canonical_checked_at=CURRENT_TIMESTAMP WHERE id=?`, id)
+	return err
+}
+
+// RekeyInput is the canonical URL an article was found to live at.
+type RekeyInput struct {
+	URL           string
+	NormalizedURL string
+	URLHash       string
+	SourceDomain  string
+}
+
+// RekeyArticle moves article id to its canonical URL. If another article
+// already has that URL the two are merged as in RecanonicalizeArticles, and
+// the surviving id is returned with merged set. The old URL hash becomes an
+// alias of the survivor, so later hits on the old URL count for it. Only
+// canonical URLs on the article's own site, or reached from an AMP cache or
+// aggregator, are accepted.
+func (s *Store) RekeyArticle(ctx context.Context, id int64, in RekeyInput) (keptID int64, merged bool, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer tx.Rollback()
+	group, err := queryCanonRows(ctx, tx, `SELECT `+canonRowColumns+` FROM articles WHERE id=? OR url_hash=? ORDER BY id`, id, in.URLHash)
+	if err != nil {
+		return 0, false, err
+	}
+	var self *canonRow
+	for _, r := range group {
+		if r.id == id {
+			self = r
+		}
+	}
+	if self == nil {
+		return 0, false, sql.ErrNoRows
+	}
+	if !urlcanon.SameSite(self.url, in.URL) {
+		return 0, false, ErrCrossSiteCanonical
+	}
+	oldHash := self.hash
+	keeper, err := mergeArticles(ctx, tx, group)
+	if err != nil {
+		return 0, false, err
+	}
+	if keeper == self && oldHash != in.URLHash {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE articles SET url=?, normalized_url=?, url_hash=?, source_domain=?, updated_at=CURRENT_TIMESTAMP WHERE id=?
+		`, in.URL, in.NormalizedURL, in.URLHash, in.SourceDomain, id); err != nil {
+			return 0, false, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE articles SET canonical_checked_at=CURRENT_TIMESTAMP WHERE id=?`, keeper.id); err != nil {
+		return 0, false, err
+	}
+	if oldHash != in.URLHash {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO url_aliases(url_hash, article_id) VALUES(?,?)
+			ON CONFLICT(url_hash) DO UPDATE SET article_id=excluded.article_id
+		`, oldHash, keeper.id); err != nil {
+			return 0, false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, err
+	}
+	merged = len(group) > 1
+	if merged {
+		if err := s.pruneStories(ctx); err != nil {
+			return keeper.id, merged, err
+		}
+	}
+	return keeper.id, merged, nil
+}
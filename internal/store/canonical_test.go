@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"discover/internal/db"
+	"discover/internal/model"
+	"discover/internal/urlcanon"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	conn, err := db.Open(filepath.Join(t.TempDir(), "discover.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return New(conn)
+}
+
+// hit builds the ingest hit for raw as the ingester would.
+func hit(t *testing.T, raw, title, content string) UpsertArticleInput {
+	t.Helper()
+	norm, domain, err := urlcanon.New(nil).Canonical(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return UpsertArticleInput{
+		URL: raw, NormalizedURL: norm, URLHash: urlcanon.Hash(norm), SourceDomain: domain,
+		Title: title, Content: content, IngestedAt: time.Now(), Engines: 1,
+	}
+}
+
+func rekeyInput(t *testing.T, raw string) RekeyInput {
+	in := hit(t, raw, "", "")
+	return RekeyInput{URL: in.URL, NormalizedURL: in.NormalizedURL, URLHash: in.URLHash, SourceDomain: in.SourceDomain}
+}
+
+func TestUpsertArticleHitFollowsAlias(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	old := hit(t, "https://example.com/story?page=amp", "Story", "First")
+	if created, err := s.UpsertArticleHit(ctx, old); err != nil || !created {
+		t.Fatalf("UpsertArticleHit = %v, %v", created, err)
+	}
+	ids, err := s.ArticleIDsByStatus(ctx, model.StatusUnread)
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("ArticleIDsByStatus = %v, %v", ids, err)
+	}
+	id := ids[0]
+
+	keptID, merged, err := s.RekeyArticle(ctx, id, rekeyInput(t, "https://www.example.com/story"))
+	if err != nil || merged || keptID != id {
+		t.Fatalf("RekeyArticle = %d, %v, %v", keptID, merged, err)
+	}
+
+	// A later hit on the old URL counts for the re-keyed article instead of
+	// bringing the old URL back as a new one.
+	if created, err := s.UpsertArticleHit(ctx, old); err != nil || created {
+		t.Fatalf("UpsertArticleHit(old) = %v, %v", created, err)
+	}
+	a, err := s.GetArticle(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.URL != "https://www.example.com/story" || a.HitCount != 2 {
+		t.Errorf("article url=%q hits=%d, want the canonical URL with 2 hits", a.URL, a.HitCount)
+	}
+	if ids, _ := s.ArticleIDsByStatus(ctx, model.StatusUnread); len(ids) != 1 {
+		t.Errorf("got %d articles, want 1", len(ids))
+	}
+}
+
+func TestRekeyArticleMergesAndRejectsOtherSites(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	for _, raw := range []string{"https://example.com/a", "https://example.com/b"} {
+		if _, err := s.UpsertArticleHit(ctx, hit(t, raw, "Story", "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := s.ArticleIDsByStatus(ctx, model.StatusUnread)
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("ArticleIDsByStatus = %v, %v", ids, err)
+	}
+
+	if _, _, err := s.RekeyArticle(ctx, ids[0], rekeyInput(t, "https://example.org/a")); !errors.Is(err, ErrCrossSiteCanonical) {
+		t.Fatalf("cross-site RekeyArticle err = %v, want ErrCrossSiteCanonical", err)
+	}
+
+	keptID, merged, err := s.RekeyArticle(ctx, ids[0], rekeyInput(t, "https://example.com/b"))
+	if err != nil || !merged {
+		t.Fatalf("RekeyArticle = %d, %v, %v", keptID, merged, err)
+	}
+	if left, _ := s.ArticleIDsByStatus(ctx, model.StatusUnread); len(left) != 1 || left[0] != keptID {
+		t.Errorf("articles left = %v, want only %d", left, keptID)
+	}
+}
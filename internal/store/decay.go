@@ -0,0 +1,27 @@
+package store
+
+// Recency decay is computed in SQL so the feed query can rank and limit by
+// decayed score without loading the whole unread pool.
+
+// articleHalfLifeSQL yields (article_id, hours) for unread articles that have
+// topics: the slowest decay among the topics that found the article, each
+// topic contributing its override or the global half-life (the placeholder)
+// when it inherits. Any topic without decay (0) exempts the article entirely.
+const articleHalfLifeSQL = `
+	SELECT article_id, CASE WHEN MIN(hours) <= 0 THEN 0 ELSE MAX(hours) END AS hours
+	FROM (
+		SELECT at.article_id, CASE WHEN t.half_life_hours >= 0 THEN t.half_life_hours ELSE ? END AS hours
+		FROM article_topics at
+		JOIN topics t ON t.id = at.topic_id
+		JOIN articles a ON a.id = at.article_id AND a.status='unread'
+	)
+	GROUP BY article_id`
+
+// decayedScoreSQL halves a positive score every half_life hours of age
+// (columns score, published_at, ingested_at and half_life). Zero or negative
+// scores and a zero half-life are returned unchanged, so decay never lifts
+// penalized items. Stored times are UTC, so their first 19 characters are
+// what julianday understands.
+const decayedScoreSQL = `CASE WHEN score > 0 AND half_life > 0 THEN
+		score * pow(2.0, -MAX(0, COALESCE((julianday('now') - julianday(substr(COALESCE(published_at, ingested_at), 1, 19))) * 24, 0)) / half_life)
+		ELSE score END`
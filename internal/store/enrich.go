@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"discover/internal/model"
+)
+
+// ListEnrichCandidates returns unread articles created in the last
+// windowHours that were not enriched yet, best score first.
+func (s *Store) ListEnrichCandidates(ctx context.Context, minScore float64, windowHours, limit int) ([]model.Article, error) {
+	return s.queryArticles(ctx, `
+		SELECT `+articleColumns+`
+		FROM articles
+		WHERE enriched_at IS NULL AND status='unread' AND score >= ?
+		  AND created_at >= datetime('now', ?)
+		ORDER BY score DESC, id DESC
+		LIMIT ?
+	`, minScore, fmt.Sprintf("-%d hours", windowHours), limit)
+}
+
+// EnrichInput is what an article page's metadata adds. Empty fields add
+// nothing.
+type EnrichInput struct {
+	ThumbnailURL string
+	PublishedAt  time.Time
+	Description  string
+	SiteName     string
+}
+
+// ApplyEnrichment fills the article's missing thumbnail and empty snippet,
+// replaces a published time that was only the ingest time, sets the site
+// name, and marks the article enriched.
+func (s *Store) ApplyEnrichment(ctx context.Context, id int64, in EnrichInput) error {
+	hasDate := !in.PublishedAt.IsZero()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE articles SET
+			thumbnail_url=CASE WHEN thumbnail_url='' THEN ? ELSE thumbnail_url END,
+			published_at=CASE WHEN published_estimated=1 AND ? THEN ? ELSE published_at END,
+			published_estimated=CASE WHEN ? THEN 0 ELSE published_estimated END,
+			content=CASE WHEN content='' THEN ? ELSE content END,
+			site_name=CASE WHEN ? <> '' THEN ? ELSE site_name END,
+			enriched_at=CURRENT_TIMESTAMP,
+			updated_at=CURRENT_TIMESTAMP
+		WHERE id=?
+	`, in.ThumbnailURL, hasDate, in.PublishedAt.UTC(), hasDate, in.Description, in.SiteName, in.SiteName, id)
+	return err
+}
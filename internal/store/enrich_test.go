@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEnrichmentSurvivesLaterHits(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	in := hit(t, "https://example.com/story", "Story", "")
+	if _, err := s.UpsertArticleHit(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.GetArticle(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	if err := s.ApplyEnrichment(ctx, a.ID, EnrichInput{Description: "From the page", PublishedAt: published}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hits without snippet or date keep what enrichment found.
+	in.IngestedAt = in.IngestedAt.Add(time.Hour)
+	if _, err := s.UpsertArticleHit(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = s.GetArticle(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if a.Content != "From the page" || !a.PublishedAt.Equal(published) {
+		t.Errorf("after an empty hit content=%q published=%v", a.Content, a.PublishedAt)
+	}
+
+	// A hit with a snippet replaces it.
+	in.Content = "From the search result"
+	if _, err := s.UpsertArticleHit(ctx, in); err != nil {
+		t.Fatal(err)
+	}
+	if a, err = s.GetArticle(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if a.Content != "From the search result" {
+		t.Errorf("content = %q, want the new snippet", a.Content)
+	}
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+const feedTokenPrefixChars = 6
+
+// CreateFeedToken issues a new output-feed token. Only its hash is stored, so
+// the plaintext token is returned once and cannot be recovered later.
+func (s *Store) CreateFeedToken(ctx context.Context, label string) (string, model.FeedToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", model.FeedToken{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	prefix := token[:feedTokenPrefixChars]
+	label = strings.TrimSpace(label)
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO feed_tokens(label, token_hash, prefix, created_at)
+		VALUES(?,?,?,CURRENT_TIMESTAMP)
+	`, label, hashFeedToken(token), prefix)
+	if err != nil {
+		return "", model.FeedToken{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", model.FeedToken{}, err
+	}
+	return token, model.FeedToken{ID: id, Label: label, Prefix: prefix, CreatedAt: time.Now().UTC()}, nil
+}
+
+func (s *Store) ListFeedTokens(ctx context.Context) ([]model.FeedToken, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, label, prefix, created_at, last_used_at FROM feed_tokens ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.FeedToken, 0)
+	for rows.Next() {
+		var t model.FeedToken
+		var createdRaw any
+		var lastUsedRaw any
+		if err := rows.Scan(&t.ID, &t.Label, &t.Prefix, &createdRaw, &lastUsedRaw); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = parseDBTime(createdRaw)
+		t.LastUsedAt = parseDBTime(lastUsedRaw)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) DeleteFeedToken(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM feed_tokens WHERE id=?`, id)
+	return err
+}
+
+// ValidateFeedToken reports whether token is a live output-feed token and
+// records its use.
+func (s *Store) ValidateFeedToken(ctx context.Context, token string) (bool, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return false, nil
+	}
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM feed_tokens WHERE token_hash=?`, hashFeedToken(token)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE feed_tokens SET last_used_at=CURRENT_TIMESTAMP WHERE id=?`, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+// HistoryCursor points at the last article of a history page. The zero value
+// starts from the newest change.
+type HistoryCursor struct {
+	ChangedAt time.Time
+	ID        int64
+}
+
+// ListHistory lists articles in the given statuses by status-change time,
+// newest first, starting after cursor. more reports whether another page
+// exists.
+func (s *Store) ListHistory(ctx context.Context, statuses []model.ArticleStatus, cursor HistoryCursor, limit int) ([]model.Article, bool, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	where := []string{"1=1"}
+	args := make([]any, 0, len(statuses)+4)
+	if len(statuses) > 0 {
+		marks := make([]string, len(statuses))
+		for i, st := range statuses {
+			marks[i] = "?"
+			args = append(args, string(st))
+		}
+		where = append(where, "status IN ("+strings.Join(marks, ",")+")")
+	}
+	if cursor.ID > 0 {
+		// status_changed_at is written by CURRENT_TIMESTAMP, so compare in
+		// the same text layout.
+		at := cursor.ChangedAt.UTC().Format("2006-01-02 15:04:05")
+		where = append(where, "(status_changed_at < ? OR (status_changed_at = ? AND id < ?))")
+		args = append(args, at, at, cursor.ID)
+	}
+	args = append(args, limit+1)
+	items, err := s.queryArticles(ctx, `
+		SELECT `+articleColumns+`
+		FROM articles
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY status_changed_at DESC, id DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	return items, more, nil
+}
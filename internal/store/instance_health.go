@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"discover/internal/model"
+)
+
+func (s *Store) ListInstanceHealth(ctx context.Context) ([]model.InstanceHealth, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT url, attempts, successes, failures, empty_results, consecutive_failures, avg_latency_ms,
+			last_error, last_error_at, last_success_at, blocked_until, updated_at
+		FROM searx_instance_health
+		ORDER BY url
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.InstanceHealth, 0, 8)
+	for rows.Next() {
+		var h model.InstanceHealth
+		var errorAt, successAt, blockedUntil, updatedAt any
+		if err := rows.Scan(&h.URL, &h.Attempts, &h.Successes, &h.Failures, &h.EmptyResults, &h.ConsecutiveFailures, &h.AvgLatencyMS,
+			&h.LastError, &errorAt, &successAt, &blockedUntil, &updatedAt); err != nil {
+			return nil, err
+		}
+		h.LastErrorAt = parseDBTime(errorAt)
+		h.LastSuccessAt = parseDBTime(successAt)
+		h.BlockedUntil = parseDBTime(blockedUntil)
+		h.UpdatedAt = parseDBTime(updatedAt)
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// InstanceHealthMap returns the recorded health of every instance by URL.
+func (s *Store) InstanceHealthMap(ctx context.Context) (map[string]model.InstanceHealth, error) {
+	list, err := s.ListInstanceHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]model.InstanceHealth, len(list))
+	for _, h := range list {
+		out[h.URL] = h
+	}
+	return out, nil
+}
+
+func (s *Store) SaveInstanceHealth(ctx context.Context, h model.InstanceHealth) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO searx_instance_health(url, attempts, successes, failures, empty_results, consecutive_failures, avg_latency_ms,
+			last_error, last_error_at, last_success_at, blocked_until, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(url) DO UPDATE SET
+			attempts=excluded.attempts,
+			successes=excluded.successes,
+			failures=excluded.failures,
+			empty_results=excluded.empty_results,
+			consecutive_failures=excluded.consecutive_failures,
+			avg_latency_ms=excluded.avg_latency_ms,
+			last_error=excluded.last_error,
+			last_error_at=excluded.last_error_at,
+			last_success_at=excluded.last_success_at,
+			blocked_until=excluded.blocked_until,
+			updated_at=CURRENT_TIMESTAMP
+	`, h.URL, h.Attempts, h.Successes, h.Failures, h.EmptyResults, h.ConsecutiveFailures, h.AvgLatencyMS,
+		h.LastError, nullTime(h.LastErrorAt), nullTime(h.LastSuccessAt), nullTime(h.BlockedUntil))
+	return err
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
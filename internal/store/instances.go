@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"discover/internal/model"
+)
+
+// searxSeededSetting marks that searxng_instances from the config file were
+// copied into searx_instances; after that the table is the source of truth.
+const searxSeededSetting = "searx_instances_seeded"
+
+const searxInstanceColumns = `id, url, provider, api_key, enabled, priority, delay_seconds, extra_params, response_format, created_at, updated_at`
+
+func (s *Store) ListSearxInstances(ctx context.Context) ([]model.SearxInstance, error) {
+	return s.listSearxInstances(ctx, `SELECT `+searxInstanceColumns+` FROM searx_instances ORDER BY priority DESC, id`)
+}
+
+func (s *Store) ListEnabledSearxInstances(ctx context.Context) ([]model.SearxInstance, error) {
+	return s.listSearxInstances(ctx, `SELECT `+searxInstanceColumns+` FROM searx_instances WHERE enabled=1 ORDER BY priority DESC, id`)
+}
+
+func (s *Store) GetSearxInstance(ctx context.Context, id int64) (model.SearxInstance, error) {
+	list, err := s.listSearxInstances(ctx, `SELECT `+searxInstanceColumns+` FROM searx_instances WHERE id=?`, id)
+	if err != nil {
+		return model.SearxInstance{}, err
+	}
+	if len(list) == 0 {
+		return model.SearxInstance{}, sql.ErrNoRows
+	}
+	return list[0], nil
+}
+
+func (s *Store) listSearxInstances(ctx context.Context, query string, args ...any) ([]model.SearxInstance, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.SearxInstance, 0, 8)
+	for rows.Next() {
+		var inst model.SearxInstance
+		var enabled int
+		var createdAt, updatedAt any
+		if err := rows.Scan(&inst.ID, &inst.URL, &inst.Provider, &inst.APIKey, &enabled, &inst.Priority, &inst.DelaySeconds, &inst.ExtraParams, &inst.ResponseFormat, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		inst.Enabled = enabled == 1
+		inst.HasAPIKey = inst.APIKey != ""
+		inst.CreatedAt = parseDBTime(createdAt)
+		inst.UpdatedAt = parseDBTime(updatedAt)
+		out = append(out, inst)
+	}
+	return out, rows.Err()
+}
+
+// UpsertSearxInstance adds an instance or updates the one with the same URL.
+// An empty APIKey keeps the stored key. Updates forget the detected response
+// format, so it is detected again with the new settings.
+func (s *Store) UpsertSearxInstance(ctx context.Context, inst model.SearxInstance) error {
+	provider, err := NormalizeProvider(inst.Provider)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO searx_instances(url, provider, api_key, enabled, priority, delay_seconds, extra_params, updated_at)
+		VALUES(?,?,?,?,?,?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(url) DO UPDATE SET
+			provider=excluded.provider,
+			api_key=CASE WHEN excluded.api_key='' THEN searx_instances.api_key ELSE excluded.api_key END,
+			enabled=excluded.enabled,
+			priority=excluded.priority,
+			delay_seconds=excluded.delay_seconds,
+			extra_params=excluded.extra_params,
+			response_format='',
+			updated_at=CURRENT_TIMESTAMP
+	`, strings.TrimSpace(inst.URL), provider, strings.TrimSpace(inst.APIKey), boolInt(inst.Enabled), inst.Priority, inst.DelaySeconds, strings.TrimSpace(inst.ExtraParams))
+	return err
+}
+
+// SetSearxInstanceFormat remembers which response format an instance serves.
+func (s *Store) SetSearxInstanceFormat(ctx context.Context, id int64, format string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE searx_instances SET response_format=? WHERE id=?`, format, id)
+	return err
+}
+
+func NormalizeProvider(provider string) (string, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	switch provider {
+	case "":
+		return model.ProviderSearxng, nil
+	case model.ProviderSearxng, model.ProviderYaCy, model.ProviderWhoogle, model.ProviderBrave:
+		return provider, nil
+	default:
+		return "", fmt.Errorf("unknown search provider %q", provider)
+	}
+}
+
+func (s *Store) DeleteSearxInstance(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM searx_instances WHERE id=?`, id)
+	return err
+}
+
+// SeedSearxInstances copies the config file's instances into the table the
+// first time it runs. Later starts leave the table alone, so instances
+// removed in the admin UI do not come back.
+func (s *Store) SeedSearxInstances(ctx context.Context, urls []string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var done string
+	err = tx.QueryRowContext(ctx, `SELECT value FROM app_settings WHERE key=?`, searxSeededSetting).Scan(&done)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	added := 0
+	for _, raw := range urls {
+		base := strings.TrimRight(strings.TrimSpace(raw), "/")
+		if base == "" {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO searx_instances(url) VALUES(?)`, base)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO app_settings(key, value, updated_at) VALUES(?, '1', CURRENT_TIMESTAMP)
+	`, searxSeededSetting); err != nil {
+		return 0, err
+	}
+	return added, tx.Commit()
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"discover/internal/model"
+)
+
+// ingestRunKeep is how many runs (with their per-topic rows) are kept.
+const ingestRunKeep = 1000
+
+// StartIngestRun records a new running run. Runs still marked running belong
+// to a process that stopped mid-run, so they are closed as interrupted first.
+func (s *Store) StartIngestRun(ctx context.Context, jobID int64, source string) (int64, error) {
+	if _, err := s.db.ExecContext(ctx, `UPDATE ingest_runs SET status=?, finished_at=CURRENT_TIMESTAMP WHERE status=?`, model.RunInterrupted, model.RunRunning); err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO ingest_runs(job_id, source, status, started_at)
+		VALUES(?,?,?,CURRENT_TIMESTAMP)
+	`, jobID, source, model.RunRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (s *Store) AddIngestRunTopic(ctx context.Context, t model.IngestRunTopic) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO ingest_run_topics(run_id, topic_id, kind, query, instance, results, new_articles, updated_articles, error, duration_ms, created_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,CURRENT_TIMESTAMP)
+	`, t.RunID, t.TopicID, t.Kind, t.Query, t.Instance, t.Results, t.NewArticles, t.UpdatedArticles, t.Error, t.DurationMS)
+	return err
+}
+
+// FinishIngestRun stores the outcome and totals of run.ID and trims old runs.
+func (s *Store) FinishIngestRun(ctx context.Context, run model.IngestRun) error {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE ingest_runs
+		SET status=?, finished_at=CURRENT_TIMESTAMP, error=?, topics=?, failed_topics=?,
+			fetched_entries=?, new_articles=?, updated_articles=?
+		WHERE id=?
+	`, run.Status, run.Error, run.Topics, run.FailedTopics, run.FetchedEntries, run.NewArticles, run.UpdatedArticles, run.ID); err != nil {
+		return err
+	}
+	var cutoff int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM ingest_runs ORDER BY id DESC LIMIT 1 OFFSET ?`, ingestRunKeep).Scan(&cutoff)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM ingest_runs WHERE id <= ?`, cutoff); err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `DELETE FROM ingest_run_topics WHERE run_id <= ?`, cutoff)
+	return err
+}
+
+const ingestRunColumns = `id, job_id, source, status, started_at, finished_at, error, topics, failed_topics,
+	fetched_entries, new_articles, updated_articles`
+
+func scanIngestRun(sc interface{ Scan(...any) error }) (model.IngestRun, error) {
+	var r model.IngestRun
+	var startedRaw any
+	var finishedRaw any
+	if err := sc.Scan(&r.ID, &r.JobID, &r.Source, &r.Status, &startedRaw, &finishedRaw, &r.Error, &r.Topics, &r.FailedTopics,
+		&r.FetchedEntries, &r.NewArticles, &r.UpdatedArticles); err != nil {
+		return model.IngestRun{}, err
+	}
+	r.StartedAt = parseDBTime(startedRaw)
+	r.FinishedAt = parseDBTime(finishedRaw)
+	return r, nil
+}
+
+// ListIngestRuns lists the most recent runs, newest first.
+func (s *Store) ListIngestRuns(ctx context.Context, limit int) ([]model.IngestRun, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+ingestRunColumns+` FROM ingest_runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.IngestRun, 0, limit)
+	for rows.Next() {
+		r, err := scanIngestRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetIngestRun returns sql.ErrNoRows when the run does not exist.
+func (s *Store) GetIngestRun(ctx context.Context, id int64) (model.IngestRun, error) {
+	return scanIngestRun(s.db.QueryRowContext(ctx, `SELECT `+ingestRunColumns+` FROM ingest_runs WHERE id=?`, id))
+}
+
+func (s *Store) ListIngestRunTopics(ctx context.Context, runID int64) ([]model.IngestRunTopic, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, run_id, topic_id, kind, query, instance, results, new_articles, updated_articles, error, duration_ms, created_at
+		FROM ingest_run_topics
+		WHERE run_id=?
+		ORDER BY id
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.IngestRunTopic, 0, 32)
+	for rows.Next() {
+		var t model.IngestRunTopic
+		var createdRaw any
+		if err := rows.Scan(&t.ID, &t.RunID, &t.TopicID, &t.Kind, &t.Query, &t.Instance, &t.Results, &t.NewArticles, &t.UpdatedArticles, &t.Error, &t.DurationMS, &createdRaw); err != nil {
+			return nil, err
+		}
+		t.CreatedAt = parseDBTime(createdRaw)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// TopicRunStats summarizes recorded runs for every topic, topics with the
+// longest streak of empty runs first.
+func (s *Store) TopicRunStats(ctx context.Context) ([]model.TopicRunStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.kind, t.query, t.enabled,
+			(SELECT COUNT(*) FROM ingest_run_topics r WHERE r.topic_id=t.id),
+			(SELECT created_at FROM ingest_run_topics r WHERE r.topic_id=t.id ORDER BY r.id DESC LIMIT 1),
+			COALESCE((SELECT results FROM ingest_run_topics r WHERE r.topic_id=t.id ORDER BY r.id DESC LIMIT 1), 0),
+			COALESCE((SELECT error FROM ingest_run_topics r WHERE r.topic_id=t.id ORDER BY r.id DESC LIMIT 1), ''),
+			(SELECT created_at FROM ingest_run_topics r WHERE r.topic_id=t.id AND r.results > 0 ORDER BY r.id DESC LIMIT 1),
+			(SELECT COUNT(*) FROM ingest_run_topics r WHERE r.topic_id=t.id AND r.id > COALESCE(
+				(SELECT MAX(x.id) FROM ingest_run_topics x WHERE x.topic_id=t.id AND x.results > 0), 0)) AS empty_streak
+		FROM topics t
+		ORDER BY empty_streak DESC, t.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.TopicRunStats, 0, 32)
+	for rows.Next() {
+		var st model.TopicRunStats
+		var enabled int
+		var lastRunRaw any
+		var lastNonEmptyRaw any
+		if err := rows.Scan(&st.TopicID, &st.Kind, &st.Query, &enabled, &st.Runs, &lastRunRaw, &st.LastResults, &st.LastError, &lastNonEmptyRaw, &st.EmptyStreak); err != nil {
+			return nil, err
+		}
+		st.Enabled = enabled == 1
+		st.LastRunAt = parseDBTime(lastRunRaw)
+		st.LastNonEmptyAt = parseDBTime(lastNonEmptyRaw)
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strconv"
+
+	"discover/internal/model"
+)
+
+// RulePenalty is one rule that matched an entry at ingest time. Penalty is
+// signed (see SignedPenalty), so boost rules carry a negative value.
+type RulePenalty struct {
+	RuleID  int64
+	Kind    string
+	Pattern string
+	Penalty float64
+}
+
+type scoreDelta struct {
+	component string
+	refID     int64
+	detail    string
+	delta     float64
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertScoreEvents(ctx context.Context, ex execer, articleID int64, deltas []scoreDelta) error {
+	for _, d := range deltas {
+		if d.delta == 0 && d.component != model.ScoreBase {
+			continue
+		}
+		if _, err := ex.ExecContext(ctx, `
+			INSERT INTO article_score_events(article_id, component, ref_id, detail, delta, created_at)
+			VALUES(?,?,?,?,?,CURRENT_TIMESTAMP)
+		`, articleID, d.component, d.refID, d.detail, d.delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hitScoreDeltas splits the score added by one ingest hit into ledger rows.
+// The rows always sum to the returned total.
+func hitScoreDeltas(in UpsertArticleInput) ([]scoreDelta, float64) {
+	deltas := []scoreDelta{
+		{component: model.ScoreBase, delta: 1.0},
+		{component: model.ScoreTopicWeight, refID: in.TopicID, detail: in.TopicQuery, delta: in.TopicWeight},
+		{component: model.ScoreEngines, detail: strconv.Itoa(maxInt(in.Engines, 1)), delta: float64(maxInt(in.Engines, 1)) * 0.25},
+		{component: model.ScoreSearx, delta: in.SearxScore * 0.25},
+		{component: model.ScoreTermBoost, refID: in.TopicID, detail: in.TopicQuery, delta: in.ExtraTitleHit},
+	}
+	for _, p := range in.Penalties {
+		component := model.ScoreRulePenalty
+		if p.Kind == model.RuleKindBoost {
+			component = model.ScoreRuleBoost
+		}
+		deltas = append(deltas, scoreDelta{component: component, refID: p.RuleID, detail: p.Pattern, delta: -p.Penalty})
+	}
+	total := 0.0
+	for _, d := range deltas {
+		total += d.delta
+	}
+	if total < -10 {
+		deltas = append(deltas, scoreDelta{component: model.ScoreFloor, delta: -10 - total})
+		total = -10
+	}
+	return deltas, total
+}
+
+// DeleteOrphanScoreEvents drops ledger rows whose article no longer exists.
+func (s *Store) DeleteOrphanScoreEvents(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM article_score_events WHERE article_id NOT IN (SELECT id FROM articles)`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ExplainArticleScore returns the score ledger of one article, grouped by
+// component and source, plus the most recent raw events. Unexplained holds any
+// score accumulated before the ledger existed.
+func (s *Store) ExplainArticleScore(ctx context.Context, id int64, eventLimit int) (model.ScoreExplanation, error) {
+	art, err := s.GetArticle(ctx, id)
+	if err != nil {
+		return model.ScoreExplanation{}, err
+	}
+	out := model.ScoreExplanation{Article: art, Components: make([]model.ScoreComponent, 0), Events: make([]model.ScoreEvent, 0)}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT component, ref_id, MAX(detail), SUM(delta), COUNT(*)
+		FROM article_score_events
+		WHERE article_id=?
+		GROUP BY component, ref_id
+		ORDER BY MIN(id)
+	`, id)
+	if err != nil {
+		return model.ScoreExplanation{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c model.ScoreComponent
+		if err := rows.Scan(&c.Component, &c.RefID, &c.Detail, &c.Delta, &c.Count); err != nil {
+			return model.ScoreExplanation{}, err
+		}
+		out.Components = append(out.Components, c)
+		out.LedgerTotal += c.Delta
+	}
+	if err := rows.Err(); err != nil {
+		return model.ScoreExplanation{}, err
+	}
+	rows.Close()
+
+	if eventLimit <= 0 {
+		eventLimit = 100
+	}
+	evRows, err := s.db.QueryContext(ctx, `
+		SELECT id, article_id, component, ref_id, detail, delta, created_at
+		FROM article_score_events
+		WHERE article_id=?
+		ORDER BY id DESC
+		LIMIT ?
+	`, id, eventLimit)
+	if err != nil {
+		return model.ScoreExplanation{}, err
+	}
+	defer evRows.Close()
+	for evRows.Next() {
+		var e model.ScoreEvent
+		var createdRaw any
+		if err := evRows.Scan(&e.ID, &e.ArticleID, &e.Component, &e.RefID, &e.Detail, &e.Delta, &createdRaw); err != nil {
+			return model.ScoreExplanation{}, err
+		}
+		e.CreatedAt = parseDBTime(createdRaw)
+		out.Events = append(out.Events, e)
+	}
+	if err := evRows.Err(); err != nil {
+		return model.ScoreExplanation{}, err
+	}
+	out.LedgerTotal = math.Round(out.LedgerTotal*1e6) / 1e6
+	out.Unexplained = math.Round((art.Score-out.LedgerTotal)*1e6) / 1e6
+	return out, nil
+}
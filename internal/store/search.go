@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+type SearchParams struct {
+	Query    string
+	Statuses []model.ArticleStatus
+	From     time.Time
+	To       time.Time
+	TopicID  int64
+	SortDate bool
+	Limit    int
+	Offset   int
+}
+
+var searchTermRe = regexp.MustCompile(`"[^"]*"|\S+`)
+
+// ftsQuery turns free text into a safe FTS5 query: every word or quoted
+// phrase becomes a quoted string (all must match) and a trailing * on a word
+// keeps prefix matching. FTS5 operators typed by the user are searched as
+// plain words.
+func ftsQuery(raw string) string {
+	parts := make([]string, 0, 8)
+	for _, m := range searchTermRe.FindAllString(raw, -1) {
+		prefix := false
+		if strings.HasPrefix(m, `"`) {
+			m = strings.Trim(m, `"`)
+		} else if strings.HasSuffix(m, "*") {
+			m = strings.TrimRight(m, "*")
+			prefix = true
+		}
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		term := `"` + strings.ReplaceAll(m, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		parts = append(parts, term)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchArticles runs a full-text search over article history. Results are
+// ordered by relevance (title matches weigh most) or by date, and more reports
+// whether another page exists.
+func (s *Store) SearchArticles(ctx context.Context, p SearchParams) ([]model.Article, bool, error) {
+	match := ftsQuery(p.Query)
+	if match == "" {
+		return nil, false, errors.New("empty search query")
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	where := []string{"1=1"}
+	args := []any{match}
+	if len(p.Statuses) > 0 {
+		marks := make([]string, len(p.Statuses))
+		for i, st := range p.Statuses {
+			marks[i] = "?"
+			args = append(args, string(st))
+		}
+		where = append(where, "status IN ("+strings.Join(marks, ",")+")")
+	}
+	if !p.From.IsZero() {
+		where = append(where, "COALESCE(published_at, ingested_at) >= ?")
+		args = append(args, p.From.UTC())
+	}
+	if !p.To.IsZero() {
+		where = append(where, "COALESCE(published_at, ingested_at) < ?")
+		args = append(args, p.To.UTC())
+	}
+	if p.TopicID > 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM article_topics at WHERE at.article_id = articles.id AND at.topic_id = ?)")
+		args = append(args, p.TopicID)
+	}
+	order := "hits.rank, id DESC"
+	if p.SortDate {
+		order = "COALESCE(published_at, ingested_at) DESC, id DESC"
+	}
+	args = append(args, p.Limit+1, p.Offset)
+	items, err := s.queryArticles(ctx, `
+		WITH hits AS (
+			SELECT rowid AS id, bm25(articles_fts, 5.0, 1.0, 2.0) AS rank
+			FROM articles_fts
+			WHERE articles_fts MATCH ?
+		)
+		SELECT `+articleColumns+`
+		FROM articles JOIN hits USING (id)
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	more := len(items) > p.Limit
+	if more {
+		items = items[:p.Limit]
+	}
+	return items, more, nil
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"discover/internal/model"
+)
+
+type UndoStats struct {
+	Actions  int64 `json:"actions"`
+	Reverted int64 `json:"reverted"`
+	Skipped  int64 `json:"skipped"`
+}
+
+// recordStatusChanges logs, as one action, the move of every article matching
+// where to status. It must run before the UPDATE that applies the change so
+// the previous status is still visible. Rows already in status are only logged
+// when the score changes too.
+func recordStatusChanges(ctx context.Context, tx *sql.Tx, source string, status model.ArticleStatus, delta float64, where string, args ...any) error {
+	actionID, err := nextStatusActionID(ctx, tx)
+	if err != nil {
+		return err
+	}
+	params := make([]any, 0, len(args)+6)
+	params = append(params, actionID, string(status), delta, source)
+	params = append(params, args...)
+	params = append(params, string(status), delta)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO article_status_events(action_id, article_id, old_status, new_status, score_delta, source, prev_changed_at, created_at)
+		SELECT ?, id, status, ?, ?, ?, status_changed_at, CURRENT_TIMESTAMP
+		FROM articles
+		WHERE (`+where+`) AND (status <> ? OR ? <> 0)
+	`, params...)
+	return err
+}
+
+func nextStatusActionID(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(action_id), 0) + 1 FROM article_status_events`).Scan(&id)
+	return id, err
+}
+
+// setStatusWhere moves every article matching where to status and logs the
+// change under source.
+func (s *Store) setStatusWhere(ctx context.Context, source string, status model.ArticleStatus, where string, args ...any) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if err := recordStatusChanges(ctx, tx, source, status, 0, where, args...); err != nil {
+		return 0, err
+	}
+	params := append([]any{string(status)}, args...)
+	res, err := tx.ExecContext(ctx, `
+		UPDATE articles
+		SET status=?, status_changed_at=CURRENT_TIMESTAMP, updated_at=CURRENT_TIMESTAMP
+		WHERE `+where, params...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// UndoStatusActions reverts the last n user actions, newest first: status,
+// status-change time and score delta go back to what they were. System
+// changes (auto-hide, dedupe, earlier undos) and batches marked seen by
+// Load Next are skipped over. An article whose status has moved on since the
+// action, or that was deleted, is left alone and counted as skipped.
+func (s *Store) UndoStatusActions(ctx context.Context, n int) (UndoStats, error) {
+	var stats UndoStats
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	actionIDs := make([]int64, 0, n)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT action_id
+		FROM article_status_events
+		WHERE undone_at IS NULL AND source NOT IN (?,?,?,?)
+		ORDER BY action_id DESC
+		LIMIT ?
+	`, model.StatusSourceAutoHide, model.StatusSourceDedupe, model.StatusSourceUndo, model.StatusSourceSeen, n)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		actionIDs = append(actionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return stats, err
+	}
+	rows.Close()
+	if len(actionIDs) == 0 {
+		return stats, nil
+	}
+
+	undoID, err := nextStatusActionID(ctx, tx)
+	if err != nil {
+		return stats, err
+	}
+	type event struct {
+		id        int64
+		articleID int64
+		oldStatus string
+		newStatus string
+		delta     float64
+	}
+	for _, actionID := range actionIDs {
+		events := make([]event, 0, 8)
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, article_id, old_status, new_status, score_delta
+			FROM article_status_events
+			WHERE action_id=?
+			ORDER BY id DESC
+		`, actionID)
+		if err != nil {
+			return stats, err
+		}
+		for rows.Next() {
+			var ev event
+			if err := rows.Scan(&ev.id, &ev.articleID, &ev.oldStatus, &ev.newStatus, &ev.delta); err != nil {
+				rows.Close()
+				return stats, err
+			}
+			events = append(events, ev)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		rows.Close()
+
+		for _, ev := range events {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO article_status_events(action_id, article_id, old_status, new_status, score_delta, source, prev_changed_at, created_at)
+				SELECT ?, id, status, ?, ?, ?, status_changed_at, CURRENT_TIMESTAMP
+				FROM articles
+				WHERE id=? AND status=?
+			`, undoID, ev.oldStatus, -ev.delta, model.StatusSourceUndo, ev.articleID, ev.newStatus)
+			if err != nil {
+				return stats, err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				stats.Skipped++
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE articles
+				SET status=?, score=score-?,
+					status_changed_at=(SELECT prev_changed_at FROM article_status_events WHERE id=?),
+					updated_at=CURRENT_TIMESTAMP
+				WHERE id=?
+			`, ev.oldStatus, ev.delta, ev.id, ev.articleID); err != nil {
+				return stats, err
+			}
+			if err := insertScoreEvents(ctx, tx, ev.articleID, []scoreDelta{{component: model.ScoreFeedback, detail: "undo " + ev.newStatus, delta: -ev.delta}}); err != nil {
+				return stats, err
+			}
+			stats.Reverted++
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE article_status_events SET undone_at=CURRENT_TIMESTAMP WHERE action_id=?`, actionID); err != nil {
+			return stats, err
+		}
+		stats.Actions++
+	}
+	return stats, tx.Commit()
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -45,63 +46,123 @@ func New(db *sql.DB) *Store {
 
 func (s *Store) DB() *sql.DB { return s.db }
 
+const topicColumns = `id, kind, query, weight, enabled, half_life_hours, categories, engines, language, time_ranges, pages, min_interval_minutes, last_fetched_at`
+
+func scanTopic(sc interface{ Scan(...any) error }) (model.Topic, error) {
+	var t model.Topic
+	var en int
+	var categories, engines, timeRanges string
+	var fetchedRaw any
+	if err := sc.Scan(&t.ID, &t.Kind, &t.Query, &t.Weight, &en, &t.HalfLifeHours, &categories, &engines, &t.Language, &timeRanges, &t.Pages, &t.MinIntervalMinutes, &fetchedRaw); err != nil {
+		return model.Topic{}, err
+	}
+	t.Enabled = en == 1
+	t.Categories = splitList(categories)
+	t.Engines = splitList(engines)
+	t.TimeRanges = splitList(timeRanges)
+	t.LastFetchedAt = parseDBTime(fetchedRaw)
+	return t, nil
+}
+
 func (s *Store) ListEnabledTopics(ctx context.Context) ([]model.Topic, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, query, weight, enabled FROM topics WHERE enabled=1 ORDER BY id`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var out []model.Topic
-	for rows.Next() {
-		var t model.Topic
-		var en int
-		if err := rows.Scan(&t.ID, &t.Query, &t.Weight, &en); err != nil {
-			return nil, err
-		}
-		t.Enabled = en == 1
-		out = append(out, t)
-	}
-	return out, rows.Err()
+	return s.listTopics(ctx, `SELECT `+topicColumns+` FROM topics WHERE enabled=1 ORDER BY id`)
 }
 
 func (s *Store) ListTopics(ctx context.Context) ([]model.Topic, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, query, weight, enabled FROM topics ORDER BY id`)
+	return s.listTopics(ctx, `SELECT `+topicColumns+` FROM topics ORDER BY id`)
+}
+
+func (s *Store) listTopics(ctx context.Context, query string) ([]model.Topic, error) {
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var out []model.Topic
 	for rows.Next() {
-		var t model.Topic
-		var en int
-		if err := rows.Scan(&t.ID, &t.Query, &t.Weight, &en); err != nil {
+		t, err := scanTopic(rows)
+		if err != nil {
 			return nil, err
 		}
-		t.Enabled = en == 1
 		out = append(out, t)
 	}
 	return out, rows.Err()
 }
 
 func (s *Store) UpsertTopic(ctx context.Context, t model.Topic) error {
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO topics(query, weight, enabled, updated_at)
-		VALUES(?,?,?,CURRENT_TIMESTAMP)
+	kind, err := NormalizeTopicKind(t.Kind)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO topics(kind, query, weight, enabled, half_life_hours, categories, engines, language, time_ranges, pages, min_interval_minutes, updated_at)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,CURRENT_TIMESTAMP)
 		ON CONFLICT(query) DO UPDATE SET
+			kind=excluded.kind,
 			weight=excluded.weight,
 			enabled=excluded.enabled,
+			half_life_hours=excluded.half_life_hours,
+			categories=excluded.categories,
+			engines=excluded.engines,
+			language=excluded.language,
+			time_ranges=excluded.time_ranges,
+			pages=excluded.pages,
+			min_interval_minutes=excluded.min_interval_minutes,
 			updated_at=CURRENT_TIMESTAMP
-	`, strings.TrimSpace(t.Query), t.Weight, boolInt(t.Enabled))
+	`, kind, strings.TrimSpace(t.Query), t.Weight, boolInt(t.Enabled), t.HalfLifeHours,
+		joinList(t.Categories), joinList(t.Engines), strings.TrimSpace(t.Language), joinList(t.TimeRanges), t.Pages, t.MinIntervalMinutes)
+	return err
+}
+
+// MarkTopicFetched records a successful fetch. The start of the run is stored,
+// not the fetch time, because MinIntervalMinutes is checked at run start.
+func (s *Store) MarkTopicFetched(ctx context.Context, id int64, runStart time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE topics SET last_fetched_at=? WHERE id=?`, runStart.UTC(), id)
 	return err
 }
 
+// joinList stores a list column as lower-cased, de-duplicated,
+// comma-separated values.
+func joinList(items []string) string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !slices.Contains(out, item) {
+			out = append(out, item)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+func splitList(raw string) []string {
+	out := []string{}
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func NormalizeTopicKind(kind string) (string, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "":
+		return model.TopicKindSearch, nil
+	case model.TopicKindSearch, model.TopicKindFeed:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown topic kind %q", kind)
+	}
+}
+
 func (s *Store) DeleteTopic(ctx context.Context, id int64) error {
 	_, err := s.db.ExecContext(ctx, `DELETE FROM topics WHERE id=?`, id)
 	return err
 }
 
 func (s *Store) ListEnabledNegativeRules(ctx context.Context) ([]model.NegativeRule, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, pattern, penalty, enabled, applied_count FROM negative_rules WHERE enabled=1 ORDER BY id`)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, kind, pattern, penalty, enabled, applied_count FROM negative_rules WHERE enabled=1 ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +171,7 @@ func (s *Store) ListEnabledNegativeRules(ctx context.Context) ([]model.NegativeR
 	for rows.Next() {
 		var r model.NegativeRule
 		var en int
-		if err := rows.Scan(&r.ID, &r.Pattern, &r.Penalty, &en, &r.AppliedCount); err != nil {
+		if err := rows.Scan(&r.ID, &r.Kind, &r.Pattern, &r.Penalty, &en, &r.AppliedCount); err != nil {
 			return nil, err
 		}
 		r.Enabled = en == 1
@@ -120,7 +181,7 @@ func (s *Store) ListEnabledNegativeRules(ctx context.Context) ([]model.NegativeR
 }
 
 func (s *Store) ListNegativeRules(ctx context.Context) ([]model.NegativeRule, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, pattern, penalty, enabled, applied_count FROM negative_rules ORDER BY id`)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, kind, pattern, penalty, enabled, applied_count FROM negative_rules ORDER BY id`)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +190,7 @@ func (s *Store) ListNegativeRules(ctx context.Context) ([]model.NegativeRule, er
 	for rows.Next() {
 		var r model.NegativeRule
 		var en int
-		if err := rows.Scan(&r.ID, &r.Pattern, &r.Penalty, &en, &r.AppliedCount); err != nil {
+		if err := rows.Scan(&r.ID, &r.Kind, &r.Pattern, &r.Penalty, &en, &r.AppliedCount); err != nil {
 			return nil, err
 		}
 		r.Enabled = en == 1
@@ -140,35 +201,41 @@ func (s *Store) ListNegativeRules(ctx context.Context) ([]model.NegativeRule, er
 
 func (s *Store) UpsertNegativeRule(ctx context.Context, rule model.NegativeRule) error {
 	pattern := strings.TrimSpace(rule.Pattern)
-	if pattern == "" {
-		return errors.New("empty pattern")
+	if err := matcher.Validate(pattern); err != nil {
+		return err
 	}
+	kind, err := NormalizeRuleKind(rule.Kind)
+	if err != nil {
+		return err
+	}
+	var prevKind string
 	var prevPenalty float64
 	var prevEnabled int
-	err := s.db.QueryRowContext(ctx, `SELECT penalty, enabled FROM negative_rules WHERE pattern=?`, pattern).Scan(&prevPenalty, &prevEnabled)
+	err = s.db.QueryRowContext(ctx, `SELECT kind, penalty, enabled FROM negative_rules WHERE pattern=?`, pattern).Scan(&prevKind, &prevPenalty, &prevEnabled)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO negative_rules(pattern, penalty, enabled, updated_at)
-		VALUES(?,?,?,CURRENT_TIMESTAMP)
+		INSERT INTO negative_rules(kind, pattern, penalty, enabled, updated_at)
+		VALUES(?,?,?,?,CURRENT_TIMESTAMP)
 		ON CONFLICT(pattern) DO UPDATE SET
+			kind=excluded.kind,
 			penalty=excluded.penalty,
 			enabled=excluded.enabled,
 			updated_at=CURRENT_TIMESTAMP
-	`, pattern, rule.Penalty, boolInt(rule.Enabled))
+	`, kind, pattern, rule.Penalty, boolInt(rule.Enabled))
 	if err != nil {
 		return err
 	}
 
 	prevActive := 0.0
 	if prevEnabled == 1 {
-		prevActive = prevPenalty
+		prevActive = SignedPenalty(prevKind, prevPenalty)
 	}
 	newActive := 0.0
 	if rule.Enabled {
-		newActive = rule.Penalty
+		newActive = SignedPenalty(kind, rule.Penalty)
 	}
 	delta := newActive - prevActive
 	if delta == 0 {
@@ -177,6 +244,27 @@ func (s *Store) UpsertNegativeRule(ctx context.Context, rule model.NegativeRule)
 	return s.ApplyRuleRetroactively(ctx, pattern, delta)
 }
 
+func NormalizeRuleKind(kind string) (string, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	switch kind {
+	case "":
+		return model.RuleKindPenalty, nil
+	case model.RuleKindPenalty, model.RuleKindBoost:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown rule kind %q", kind)
+	}
+}
+
+// SignedPenalty is the amount a rule subtracts from a matching article's
+// score: boost rules store a positive weight and subtract a negative one.
+func SignedPenalty(kind string, penalty float64) float64 {
+	if kind == model.RuleKindBoost {
+		return -penalty
+	}
+	return penalty
+}
+
 func (s *Store) DeleteNegativeRule(ctx context.Context, id int64) error {
 	_, err := s.db.ExecContext(ctx, `DELETE FROM negative_rules WHERE id=?`, id)
 	return err
@@ -184,8 +272,9 @@ func (s *Store) DeleteNegativeRule(ctx context.Context, id int64) error {
 
 func (s *Store) ApplyRuleRetroactively(ctx context.Context, pattern string, penalty float64) error {
 	pattern = strings.TrimSpace(pattern)
-	if pattern == "" {
-		return errors.New("empty pattern")
+	expr, err := matcher.Compile(pattern)
+	if err != nil {
+		return err
 	}
 	type unreadArticle struct {
 		id           int64
@@ -222,6 +311,11 @@ func (s *Store) ApplyRuleRetroactively(ctx context.Context, pattern string, pena
 	}
 	defer tx.Rollback()
 
+	var ruleID int64
+	var ruleKind string
+	if err := tx.QueryRowContext(ctx, `SELECT id, kind FROM negative_rules WHERE pattern=?`, pattern).Scan(&ruleID, &ruleKind); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
 	stmt, err := tx.PrepareContext(ctx, `UPDATE articles SET score = score - ?, updated_at=CURRENT_TIMESTAMP WHERE id=?`)
 	if err != nil {
 		return err
@@ -230,15 +324,18 @@ func (s *Store) ApplyRuleRetroactively(ctx context.Context, pattern string, pena
 	matches := int64(0)
 
 	for _, a := range articles {
-		if !matcher.MatchRule(pattern, a.title, a.content, a.sourceDomain, a.articleURL) {
+		if !expr.Match(matcher.NewDoc(a.title, a.content, a.sourceDomain, a.articleURL)) {
 			continue
 		}
 		if _, err := stmt.ExecContext(ctx, penalty, a.id); err != nil {
 			return err
 		}
+		if err := insertScoreEvents(ctx, tx, a.id, []scoreDelta{{component: model.ScoreRuleRetroactive, refID: ruleID, detail: pattern, delta: -penalty}}); err != nil {
+			return err
+		}
 		matches++
 	}
-	if matches > 0 && penalty > 0 {
+	if matches > 0 && SignedPenalty(ruleKind, penalty) > 0 {
 		if _, err := tx.ExecContext(ctx, `
 			UPDATE negative_rules
 			SET applied_count = applied_count + ?, updated_at=CURRENT_TIMESTAMP
@@ -301,6 +398,34 @@ func (s *Store) TopicStats(ctx context.Context) (map[int64]TopicStats, error) {
 	return out, rows.Err()
 }
 
+func (s *Store) TopicQueriesForArticles(ctx context.Context, ids []int64) (map[int64][]string, error) {
+	out := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	q, args := inClause(ids)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT at.article_id, t.query
+		FROM article_topics at
+		JOIN topics t ON t.id = at.topic_id
+		WHERE at.article_id IN (`+q+`)
+		ORDER BY at.article_id, t.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var articleID int64
+		var query string
+		if err := rows.Scan(&articleID, &query); err != nil {
+			return nil, err
+		}
+		out[articleID] = append(out[articleID], query)
+	}
+	return out, rows.Err()
+}
+
 type UpsertArticleInput struct {
 	URL           string
 	NormalizedURL string
@@ -312,40 +437,60 @@ type UpsertArticleInput struct {
 	PublishedAt   time.Time
 	IngestedAt    time.Time
 	TopicID       int64
+	TopicQuery    string
 	TopicWeight   float64
 	Engines       int
 	SearxScore    float64
 	ExtraTitleHit float64
-	Penalty       float64
+	Penalties     []RulePenalty
 }
 
-func (s *Store) UpsertArticleHit(ctx context.Context, in UpsertArticleInput) error {
-	base := 1.0 + in.TopicWeight + float64(maxInt(in.Engines, 1))*0.25 + in.SearxScore*0.25 + in.ExtraTitleHit - in.Penalty
-	if base < -10 {
-		base = -10
-	}
-	if in.PublishedAt.IsZero() {
+// UpsertArticleHit stores one ingest hit and reports whether it created a new
+// article. A hit without a snippet keeps the stored one, which enrichment
+// may have filled.
+func (s *Store) UpsertArticleHit(ctx context.Context, in UpsertArticleInput) (bool, error) {
+	deltas, base := hitScoreDeltas(in)
+	// Without a date the ingest time stands in until a hit or enrichment
+	// brings a real one; a known date is never replaced by a guess.
+	estimated := in.PublishedAt.IsZero()
+	if estimated {
 		in.PublishedAt = in.IngestedAt
 	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer tx.Rollback()
 
+	// A URL that was resolved to a canonical article (RekeyArticle) is a hit
+	// on that article.
+	var aliasHash, aliasDomain string
+	err = tx.QueryRowContext(ctx, `
+		SELECT a.url_hash, a.source_domain
+		FROM url_aliases ua JOIN articles a ON a.id = ua.article_id
+		WHERE ua.url_hash=? AND NOT EXISTS (SELECT 1 FROM articles WHERE url_hash=?)
+	`, in.URLHash, in.URLHash).Scan(&aliasHash, &aliasDomain)
+	switch {
+	case err == nil:
+		in.URLHash, in.SourceDomain = aliasHash, aliasDomain
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, err
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO articles(
 			url, normalized_url, url_hash, title, content, thumbnail_url,
-			source_domain, published_at, ingested_at, status, score, hit_count,
-			engine_count, searx_score, updated_at
-		) VALUES(?,?,?,?,?,?,?,?,?,'unread',?,?,?, ?, CURRENT_TIMESTAMP)
+			source_domain, published_at, published_estimated, ingested_at, status, score, hit_count,
+			engine_count, searx_score, status_changed_at, updated_at
+		) VALUES(?,?,?,?,?,?,?,?,?,?,'unread',?,?,?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		ON CONFLICT(url_hash) DO UPDATE SET
 			title=excluded.title,
-			content=excluded.content,
+			content=CASE WHEN excluded.content <> '' THEN excluded.content ELSE articles.content END,
 			thumbnail_url=CASE WHEN excluded.thumbnail_url <> '' THEN excluded.thumbnail_url ELSE articles.thumbnail_url END,
 			source_domain=excluded.source_domain,
-			published_at=COALESCE(excluded.published_at, articles.published_at),
+			published_at=CASE WHEN excluded.published_estimated=1 THEN articles.published_at ELSE excluded.published_at END,
+			published_estimated=CASE WHEN excluded.published_estimated=1 THEN articles.published_estimated ELSE 0 END,
 			ingested_at=excluded.ingested_at,
 			score=articles.score + ?,
 			hit_count=articles.hit_count + 1,
@@ -353,27 +498,34 @@ func (s *Store) UpsertArticleHit(ctx context.Context, in UpsertArticleInput) err
 			searx_score=MAX(articles.searx_score, excluded.searx_score),
 			updated_at=CURRENT_TIMESTAMP
 	`, in.URL, in.NormalizedURL, in.URLHash, in.Title, in.Content, in.ThumbnailURL,
-		in.SourceDomain, in.PublishedAt.UTC(), in.IngestedAt.UTC(), base, 1, in.Engines, in.SearxScore, base)
+		in.SourceDomain, in.PublishedAt.UTC(), boolInt(estimated), in.IngestedAt.UTC(), base, 1, in.Engines, in.SearxScore, base)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	var articleID int64
-	if err := tx.QueryRowContext(ctx, `SELECT id FROM articles WHERE url_hash=?`, in.URLHash).Scan(&articleID); err != nil {
-		return err
+	var hits int
+	if err := tx.QueryRowContext(ctx, `SELECT id, hit_count FROM articles WHERE url_hash=?`, in.URLHash).Scan(&articleID, &hits); err != nil {
+		return false, err
 	}
 
 	if in.TopicID > 0 {
 		_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO article_topics(article_id, topic_id) VALUES(?,?)`, articleID, in.TopicID)
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
+	if err := insertScoreEvents(ctx, tx, articleID, deltas); err != nil {
+		return false, err
+	}
 
-	return tx.Commit()
+	return hits == 1, tx.Commit()
 }
 
-func (s *Store) FetchTopUnread(ctx context.Context, limit int, minScore float64) ([]model.Article, error) {
+// FetchTopUnread returns the next feed batch. Candidates are ranked by their
+// recency-decayed score (see decayedScoreSQL); a halfLifeHours of 0 with no
+// topic overrides keeps the plain score order.
+func (s *Store) FetchTopUnread(ctx context.Context, limit int, minScore, halfLifeHours float64) ([]model.Article, error) {
 	queryLimit := limit * 6
 	if queryLimit < 50 {
 		queryLimit = 50
@@ -381,34 +533,54 @@ func (s *Store) FetchTopUnread(ctx context.Context, limit int, minScore float64)
 	if queryLimit > 600 {
 		queryLimit = 600
 	}
+	// Coverage of a story the user has already been shown stays out of the
+	// feed; articles first ingested after that still show up.
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, url, normalized_url, url_hash, title, content, thumbnail_url,
-			source_domain, COALESCE(published_at, ingested_at), ingested_at,
-			status, score, hit_count, engine_count, searx_score
-		FROM articles
-		WHERE status='unread' AND score >= ?
-		ORDER BY score DESC, COALESCE(published_at, ingested_at) DESC, id DESC
+		WITH article_hl AS (`+articleHalfLifeSQL+`),
+		pool AS (
+			SELECT articles.*, COALESCE(article_hl.hours, ?) AS half_life
+			FROM articles
+			LEFT JOIN article_hl ON article_hl.article_id = articles.id
+			WHERE status='unread' AND score >= ?
+			  AND (story_id IS NULL OR NOT EXISTS (
+				SELECT 1 FROM articles shown
+				WHERE shown.story_id = articles.story_id AND shown.status IN ('seen','read','useful')
+				  AND COALESCE(shown.status_changed_at, shown.updated_at) >= articles.created_at
+			  ))
+		)
+		SELECT `+articleColumns+`, `+decayedScoreSQL+` AS decayed
+		FROM pool
+		ORDER BY decayed DESC, score DESC, COALESCE(published_at, ingested_at) DESC, id DESC
 		LIMIT ?
-	`, minScore, queryLimit)
+	`, halfLifeHours, halfLifeHours, minScore, queryLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	candidates := make([]model.Article, 0, queryLimit)
+	for rows.Next() {
+		var decayed float64
+		a, err := scanArticle(rows, &decayed)
+		if err != nil {
+			return nil, err
+		}
+		a.DecayedScore = decayed
+		candidates = append(candidates, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	out := make([]model.Article, 0, limit)
 	seenSubject := make(map[string]struct{}, limit*2)
-	for rows.Next() {
-		var a model.Article
-		var status string
-		var publishedRaw any
-		var ingestedRaw any
-		if err := rows.Scan(&a.ID, &a.URL, &a.NormalizedURL, &a.URLHash, &a.Title, &a.Content, &a.ThumbnailURL,
-			&a.SourceDomain, &publishedRaw, &ingestedRaw, &status, &a.Score, &a.HitCount, &a.EngineCount, &a.SearxScore); err != nil {
-			return nil, err
+	seenStory := make(map[int64]struct{}, limit*2)
+	for _, a := range candidates {
+		if a.StoryID > 0 {
+			if _, ok := seenStory[a.StoryID]; ok {
+				continue
+			}
+			seenStory[a.StoryID] = struct{}{}
 		}
-		a.PublishedAt = parseDBTime(publishedRaw)
-		a.IngestedAt = parseDBTime(ingestedRaw)
-		a.Status = model.ArticleStatus(status)
 		key := subjectKey(a.Title)
 		if key != "" {
 			if _, ok := seenSubject[key]; ok {
@@ -421,32 +593,69 @@ func (s *Store) FetchTopUnread(ctx context.Context, limit int, minScore float64)
 			break
 		}
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	return out, nil
 }
 
-func (s *Store) MarkIDsAsSeen(ctx context.Context, ids []int64) error {
+func (s *Store) MarkIDsAsSeen(ctx context.Context, ids []int64, source string) error {
 	if len(ids) == 0 {
 		return nil
 	}
 	q, args := inClause(ids)
-	_, err := s.db.ExecContext(ctx, `UPDATE articles SET status='seen', last_seen_at=CURRENT_TIMESTAMP, updated_at=CURRENT_TIMESTAMP WHERE status='unread' AND id IN (`+q+`)`, args...)
-	return err
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	where := `status='unread' AND id IN (` + q + `)`
+	if err := recordStatusChanges(ctx, tx, source, model.StatusSeen, 0, where, args...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE articles SET status='seen', last_seen_at=CURRENT_TIMESTAMP, status_changed_at=CURRENT_TIMESTAMP, updated_at=CURRENT_TIMESTAMP WHERE `+where, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (s *Store) MarkIDStatus(ctx context.Context, id int64, status model.ArticleStatus, delta float64) error {
-	_, err := s.db.ExecContext(ctx, `
+func (s *Store) MarkIDStatus(ctx context.Context, id int64, status model.ArticleStatus, delta float64, source string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := recordStatusChanges(ctx, tx, source, status, delta, `id=?`, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
 		UPDATE articles
-		SET status=?, score=score+?, updated_at=CURRENT_TIMESTAMP
+		SET status=?, score=score+?,
+			status_changed_at=CASE WHEN status=? THEN status_changed_at ELSE CURRENT_TIMESTAMP END,
+			updated_at=CURRENT_TIMESTAMP
 		WHERE id=?
-	`, string(status), delta, id)
+	`, string(status), delta, string(status), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		if err := insertScoreEvents(ctx, tx, id, []scoreDelta{{component: model.ScoreFeedback, detail: string(status), delta: delta}}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) MarkRead(ctx context.Context, id int64, source string) error {
+	_, err := s.setStatusWhere(ctx, source, model.StatusRead, `id=?`, id)
 	return err
 }
 
-func (s *Store) MarkRead(ctx context.Context, id int64) error {
-	_, err := s.db.ExecContext(ctx, `UPDATE articles SET status='read', updated_at=CURRENT_TIMESTAMP WHERE id=?`, id)
+// MarkIDsRead marks the unread or seen articles among ids read, as one
+// action.
+func (s *Store) MarkIDsRead(ctx context.Context, ids []int64, source string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	q, args := inClause(ids)
+	_, err := s.setStatusWhere(ctx, source, model.StatusRead, `status IN ('unread','seen') AND id IN (`+q+`)`, args...)
 	return err
 }
 
@@ -484,19 +693,24 @@ func (s *Store) CullOldUnread(ctx context.Context, olderThanDays int, maxScore f
 	if err != nil {
 		return 0, err
 	}
-	return res.RowsAffected()
+	deleted, err := res.RowsAffected()
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+	if _, err := s.DeleteOrphanScoreEvents(ctx); err != nil {
+		return deleted, err
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM url_aliases WHERE article_id NOT IN (SELECT id FROM articles)`); err != nil {
+		return deleted, err
+	}
+	if err := s.pruneStories(ctx); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
 }
 
 func (s *Store) HideUnreadBelowScore(ctx context.Context, threshold float64) (int64, error) {
-	res, err := s.db.ExecContext(ctx, `
-		UPDATE articles
-		SET status='hidden', updated_at=CURRENT_TIMESTAMP
-		WHERE status='unread' AND score < ?
-	`, threshold)
-	if err != nil {
-		return 0, err
-	}
-	return res.RowsAffected()
+	return s.setStatusWhere(ctx, model.StatusSourceAutoHide, model.StatusHidden, `status='unread' AND score < ?`, threshold)
 }
 
 func (s *Store) HideIngestTitleDuplicates(ctx context.Context, ingestedAt time.Time, keyChars int) (IngestDedupeStats, error) {
@@ -607,7 +821,7 @@ func (s *Store) HideIngestTitleDuplicates(ctx context.Context, ingestedAt time.T
 		ids = append(ids, id)
 	}
 	q, args := inClause(ids)
-	_, err = s.db.ExecContext(ctx, `UPDATE articles SET status='hidden', updated_at=CURRENT_TIMESTAMP WHERE status='unread' AND id IN (`+q+`)`, args...)
+	_, err = s.setStatusWhere(ctx, model.StatusSourceDedupe, model.StatusHidden, `status='unread' AND id IN (`+q+`)`, args...)
 	if err != nil {
 		return IngestDedupeStats{}, err
 	}
@@ -725,7 +939,7 @@ func (s *Store) HideAllUnreadTitleDuplicates(ctx context.Context, keyChars int)
 		ids = append(ids, id)
 	}
 	q, args := inClause(ids)
-	_, err = s.db.ExecContext(ctx, `UPDATE articles SET status='hidden', updated_at=CURRENT_TIMESTAMP WHERE status='unread' AND id IN (`+q+`)`, args...)
+	_, err = s.setStatusWhere(ctx, model.StatusSourceDedupe, model.StatusHidden, `status='unread' AND id IN (`+q+`)`, args...)
 	if err != nil {
 		return IngestDedupeStats{}, err
 	}
@@ -813,6 +1027,7 @@ func parseDBTimeString(s string) time.Time {
 	layouts := []string{
 		time.RFC3339Nano,
 		time.RFC3339,
+		"2006-01-02 15:04:05.999999999 -0700 MST",
 		"2006-01-02 15:04:05.999999999-07:00",
 		"2006-01-02 15:04:05.999999999",
 		"2006-01-02 15:04:05",
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"discover/internal/cluster"
+	"discover/internal/model"
+)
+
+type StoryStats struct {
+	Assigned int64 `json:"assigned"`
+	Joined   int64 `json:"joined"`
+	Created  int64 `json:"created"`
+}
+
+// AssignStories clusters articles ingested within window that have no story
+// yet. Each one joins the story of its most similar clustered neighbour when
+// the estimated Jaccard similarity reaches threshold, otherwise it starts a new
+// story. Neighbours are looked up through an LSH index (cluster.Index) rather
+// than compared with the whole window. Articles are processed in id order, so
+// coverage from the same run can join a story created moments earlier.
+func (s *Store) AssignStories(ctx context.Context, window time.Duration, threshold float64) (StoryStats, error) {
+	var stats StoryStats
+	since := time.Now().Add(-window).UTC()
+
+	pool := cluster.NewIndex(threshold)
+	poolStories := make([]int64, 0, 256)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT story_id, minhash
+		FROM articles
+		WHERE story_id IS NOT NULL AND minhash IS NOT NULL AND ingested_at >= ?
+	`, since)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var storyID int64
+		var raw []byte
+		if err := rows.Scan(&storyID, &raw); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		if sig := cluster.Decode(raw); sig != nil {
+			pool.Add(sig)
+			poolStories = append(poolStories, storyID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return stats, err
+	}
+	rows.Close()
+
+	type pending struct {
+		id      int64
+		title   string
+		content string
+	}
+	todo := make([]pending, 0, 64)
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT id, title, content
+		FROM articles
+		WHERE story_id IS NULL AND ingested_at >= ?
+		ORDER BY id
+	`, since)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.title, &p.content); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		todo = append(todo, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return stats, err
+	}
+	rows.Close()
+	if len(todo) == 0 {
+		return stats, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+	for _, p := range todo {
+		sig := cluster.Signature(p.title, p.content)
+		var storyID int64
+		best := 0.0
+		if sig != nil && threshold > 0 {
+			for _, pos := range pool.Candidates(sig) {
+				if sim := cluster.Similarity(sig, pool.Signature(pos)); sim >= threshold && sim > best {
+					best = sim
+					storyID = poolStories[pos]
+				}
+			}
+		}
+		if storyID > 0 {
+			if _, err := tx.ExecContext(ctx, `UPDATE stories SET article_count = article_count + 1, updated_at=CURRENT_TIMESTAMP WHERE id=?`, storyID); err != nil {
+				return stats, err
+			}
+			stats.Joined++
+		} else {
+			res, err := tx.ExecContext(ctx, `INSERT INTO stories(title, article_count, created_at, updated_at) VALUES(?,1,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP)`, p.title)
+			if err != nil {
+				return stats, err
+			}
+			if storyID, err = res.LastInsertId(); err != nil {
+				return stats, err
+			}
+			stats.Created++
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE articles SET story_id=?, minhash=? WHERE id=?`, storyID, cluster.Encode(sig), p.id); err != nil {
+			return stats, err
+		}
+		stats.Assigned++
+		if sig != nil {
+			pool.Add(sig)
+			poolStories = append(poolStories, storyID)
+		}
+	}
+	return stats, tx.Commit()
+}
+
+// pruneStories recounts story sizes and drops stories whose articles were all
+// deleted.
+func (s *Store) pruneStories(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM stories WHERE id NOT IN (SELECT story_id FROM articles WHERE story_id IS NOT NULL)`); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `UPDATE stories SET article_count=(SELECT COUNT(*) FROM articles WHERE articles.story_id=stories.id)`)
+	return err
+}
+
+// GetStory returns sql.ErrNoRows when the story does not exist.
+func (s *Store) GetStory(ctx context.Context, id int64) (model.Story, error) {
+	var st model.Story
+	var createdRaw any
+	var updatedRaw any
+	err := s.db.QueryRowContext(ctx, `SELECT id, title, article_count, created_at, updated_at FROM stories WHERE id=?`, id).
+		Scan(&st.ID, &st.Title, &st.ArticleCount, &createdRaw, &updatedRaw)
+	if err != nil {
+		return model.Story{}, err
+	}
+	st.CreatedAt = parseDBTime(createdRaw)
+	st.UpdatedAt = parseDBTime(updatedRaw)
+	return st, nil
+}
+
+// ListStoryArticles lists every article of a story in any status, newest
+// first.
+func (s *Store) ListStoryArticles(ctx context.Context, storyID int64) ([]model.Article, error) {
+	return s.queryArticles(ctx, `
+		SELECT `+articleColumns+`
+		FROM articles
+		WHERE story_id=?
+		ORDER BY COALESCE(published_at, ingested_at) DESC, id DESC
+	`, storyID)
+}
+
+func (s *Store) StorySizes(ctx context.Context, storyIDs []int64) (map[int64]int, error) {
+	out := make(map[int64]int, len(storyIDs))
+	if len(storyIDs) == 0 {
+		return out, nil
+	}
+	q, args := inClause(storyIDs)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, article_count FROM stories WHERE id IN (`+q+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		out[id] = n
+	}
+	return out, rows.Err()
+}
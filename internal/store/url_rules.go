@@ -0,0 +1,270 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"discover/internal/model"
+)
+
+func (s *Store) ListURLRules(ctx context.Context) ([]model.URLRule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, domain, keep_params, strip_params, created_at, updated_at FROM url_rules ORDER BY domain`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]model.URLRule, 0, 8)
+	for rows.Next() {
+		var r model.URLRule
+		var createdAt, updatedAt any
+		if err := rows.Scan(&r.ID, &r.Domain, &r.KeepParams, &r.StripParams, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		r.CreatedAt = parseDBTime(createdAt)
+		r.UpdatedAt = parseDBTime(updatedAt)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UpsertURLRule adds a rule or updates the one for the same domain.
+func (s *Store) UpsertURLRule(ctx context.Context, r model.URLRule) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO url_rules(domain, keep_params, strip_params, updated_at)
+		VALUES(?,?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(domain) DO UPDATE SET
+			keep_params=excluded.keep_params,
+			strip_params=excluded.strip_params,
+			updated_at=CURRENT_TIMESTAMP
+	`, strings.TrimSpace(r.Domain), strings.TrimSpace(r.KeepParams), strings.TrimSpace(r.StripParams))
+	return err
+}
+
+func (s *Store) DeleteURLRule(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM url_rules WHERE id=?`, id)
+	return err
+}
+
+// CanonicalizeFunc maps a stored article URL to its normalized form and hash.
+type CanonicalizeFunc func(raw string) (normalized, hash string, err error)
+
+// RecanonicalizeStats reports a RecanonicalizeArticles pass. Merged counts the
+// duplicate rows folded into another article and deleted.
+type RecanonicalizeStats struct {
+	Scanned  int `json:"scanned"`
+	Rehashed int `json:"rehashed"`
+	Merged   int `json:"merged"`
+	Skipped  int `json:"skipped"`
+}
+
+type canonRow struct {
+	id          int64
+	url         string
+	hash        string
+	newNorm     string
+	newHash     string
+	status      string
+	thumbnail   string
+	hitCount    int
+	engineCount int
+	score       float64
+	searxScore  float64
+	publishedAt time.Time
+	lastSeenAt  time.Time
+	storyID     sql.NullInt64
+}
+
+// statusRank picks which of several merged rows survives: the one the user
+// acted on most, then the oldest.
+var statusRank = map[string]int{
+	string(model.StatusUseful): 4,
+	string(model.StatusRead):   3,
+	string(model.StatusHidden): 2,
+	string(model.StatusSeen):   1,
+}
+
+// RecanonicalizeArticles recomputes normalized_url and url_hash of every
+// article with canon, for when the canonicalization rules change. Rows that
+// end up with the same hash are merged into one: hits and score add up (their
+// score events move along), topics, status events and the story carry over,
+// and the earliest published and latest seen times win. URLs canon rejects
+// are left alone. Rows are read and written in one transaction, so hits
+// stored meanwhile wait for it instead of being overwritten.
+func (s *Store) RecanonicalizeArticles(ctx context.Context, canon CanonicalizeFunc) (RecanonicalizeStats, error) {
+	var stats RecanonicalizeStats
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+	all, err := queryCanonRows(ctx, tx, `SELECT `+canonRowColumns+` FROM articles ORDER BY id`)
+	if err != nil {
+		return stats, err
+	}
+	stats.Scanned = len(all)
+
+	groups := make(map[string][]*canonRow, len(all))
+	order := make([]string, 0, len(all))
+	for _, r := range all {
+		norm, hash, err := canon(r.url)
+		if err != nil {
+			stats.Skipped++
+			norm, hash = "", r.hash
+		}
+		r.newNorm, r.newHash = norm, hash
+		if _, ok := groups[hash]; !ok {
+			order = append(order, hash)
+		}
+		groups[hash] = append(groups[hash], r)
+	}
+
+	changed := make([]*canonRow, 0, 64)
+	for _, hash := range order {
+		group := groups[hash]
+		keeper, err := mergeArticles(ctx, tx, group)
+		if err != nil {
+			return stats, err
+		}
+		stats.Merged += len(group) - 1
+		if keeper.newNorm != "" && keeper.newHash != keeper.hash {
+			changed = append(changed, keeper)
+		}
+	}
+	// Move changed rows out of the way first: a new hash may still be held
+	// by another row that is about to change too.
+	for _, r := range changed {
+		if _, err := tx.ExecContext(ctx, `UPDATE articles SET url_hash=? WHERE id=?`, "rehash:"+r.hash, r.id); err != nil {
+			return stats, err
+		}
+	}
+	for _, r := range changed {
+		if _, err := tx.ExecContext(ctx, `UPDATE articles SET normalized_url=?, url_hash=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, r.newNorm, r.newHash, r.id); err != nil {
+			return stats, err
+		}
+	}
+	stats.Rehashed = len(changed)
+	if err := tx.Commit(); err != nil {
+		return stats, err
+	}
+	if stats.Merged > 0 {
+		if err := s.pruneStories(ctx); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// urlCanonVersionKey records the urlcanon.Version that stored url_hash
+// values follow.
+const urlCanonVersionKey = "url_canon_version"
+
+// UpgradeURLCanonicalization re-keys all articles when they were stored under
+// an older canonicalization version, so new hits keep matching them, and
+// records version. It reports whether a pass ran.
+func (s *Store) UpgradeURLCanonicalization(ctx context.Context, version int, canon CanonicalizeFunc) (RecanonicalizeStats, bool, error) {
+	current, err := s.GetSettingInt(ctx, urlCanonVersionKey, 0)
+	if err != nil || current >= version {
+		return RecanonicalizeStats{}, false, err
+	}
+	stats, err := s.RecanonicalizeArticles(ctx, canon)
+	if err != nil {
+		return stats, true, err
+	}
+	return stats, true, s.SetSetting(ctx, urlCanonVersionKey, strconv.Itoa(version))
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+const canonRowColumns = `id, url, url_hash, status, thumbnail_url, hit_count, engine_count, score, searx_score, published_at, last_seen_at, story_id`
+
+func queryCanonRows(ctx context.Context, q queryer, query string, args ...any) ([]*canonRow, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]*canonRow, 0, 64)
+	for rows.Next() {
+		r := &canonRow{}
+		var publishedAt, lastSeenAt any
+		if err := rows.Scan(&r.id, &r.url, &r.hash, &r.status, &r.thumbnail, &r.hitCount, &r.engineCount, &r.score, &r.searxScore, &publishedAt, &lastSeenAt, &r.storyID); err != nil {
+			return nil, err
+		}
+		r.publishedAt = parseDBTime(publishedAt)
+		r.lastSeenAt = parseDBTime(lastSeenAt)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// mergeArticles folds every row of group into the one with the highest
+// status rank (the first on ties) and returns it.
+func mergeArticles(ctx context.Context, tx *sql.Tx, group []*canonRow) (*canonRow, error) {
+	keeper := group[0]
+	for _, r := range group[1:] {
+		if statusRank[r.status] > statusRank[keeper.status] {
+			keeper = r
+		}
+	}
+	if len(group) == 1 {
+		return keeper, nil
+	}
+	for _, r := range group {
+		if r == keeper {
+			continue
+		}
+		if err := mergeArticleInto(ctx, tx, keeper, r); err != nil {
+			return nil, err
+		}
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE articles
+		SET hit_count=?, engine_count=?, score=?, searx_score=?, published_at=?, last_seen_at=?, thumbnail_url=?, story_id=?, updated_at=CURRENT_TIMESTAMP
+		WHERE id=?
+	`, keeper.hitCount, keeper.engineCount, keeper.score, keeper.searxScore, nullTime(keeper.publishedAt), nullTime(keeper.lastSeenAt), keeper.thumbnail, keeper.storyID, keeper.id)
+	return keeper, err
+}
+
+// mergeArticleInto folds dup into keeper's in-memory fields and moves its
+// topics, events and URL aliases over before deleting it.
+func mergeArticleInto(ctx context.Context, tx *sql.Tx, keeper, dup *canonRow) error {
+	keeper.hitCount += dup.hitCount
+	keeper.engineCount = maxInt(keeper.engineCount, dup.engineCount)
+	keeper.score += dup.score
+	if dup.searxScore > keeper.searxScore {
+		keeper.searxScore = dup.searxScore
+	}
+	if !dup.publishedAt.IsZero() && (keeper.publishedAt.IsZero() || dup.publishedAt.Before(keeper.publishedAt)) {
+		keeper.publishedAt = dup.publishedAt
+	}
+	if dup.lastSeenAt.After(keeper.lastSeenAt) {
+		keeper.lastSeenAt = dup.lastSeenAt
+	}
+	if keeper.thumbnail == "" {
+		keeper.thumbnail = dup.thumbnail
+	}
+	if !keeper.storyID.Valid {
+		keeper.storyID = dup.storyID
+	}
+	stmts := []string{
+		`INSERT OR IGNORE INTO article_topics(article_id, topic_id) SELECT ?, topic_id FROM article_topics WHERE article_id=?`,
+		`UPDATE article_score_events SET article_id=? WHERE article_id=?`,
+		`UPDATE article_status_events SET article_id=? WHERE article_id=?`,
+		`UPDATE url_aliases SET article_id=? WHERE article_id=?`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt, keeper.id, dup.id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM article_topics WHERE article_id=?`, dup.id); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE id=?`, dup.id)
+	return err
+}
@@ -0,0 +1,74 @@
+package urlcanon
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// secondLevelSuffixes are public suffixes of two labels under which sites
+// register their names, such as example.co.uk. Without a full public suffix
+// list these are the common ones; elsewhere the last two labels name a site.
+var secondLevelSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true, "me.uk": true, "ltd.uk": true, "plc.uk": true,
+	"com.au": true, "net.au": true, "org.au": true, "edu.au": true, "gov.au": true,
+	"co.nz": true, "org.nz": true, "govt.nz": true,
+	"co.jp": true, "ne.jp": true, "or.jp": true, "ac.jp": true,
+	"co.kr": true, "or.kr": true, "co.in": true, "net.in": true, "org.in": true,
+	"co.za": true, "org.za": true, "co.il": true, "org.il": true,
+	"com.br": true, "com.ar": true, "com.mx": true, "com.co": true, "com.tr": true,
+	"com.cn": true, "com.hk": true, "com.tw": true, "com.sg": true, "com.my": true,
+	"com.ua": true, "com.pl": true, "com.es": true, "com.pt": true,
+}
+
+// crossSiteHosts serve other sites' articles: AMP caches and news
+// aggregators whose pages name the publisher's URL as canonical. Subdomains
+// are included.
+var crossSiteHosts = []string{
+	"ampproject.org", "ampproject.net",
+	"google.com", "googleusercontent.com", "feedburner.com",
+	"bing.com", "msn.com", "yahoo.com", "flipboard.com", "newsbreak.com", "ground.news",
+}
+
+// RegistrableDomain returns the part of host a site registers: the last two
+// labels, or three under a known second-level suffix. IP addresses and
+// single labels are returned as they are.
+func RegistrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) >= 3 && secondLevelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	if len(labels) <= n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// SameSite reports whether to may replace from as an article's URL: both
+// are on the same registrable domain, or from is on an AMP cache or news
+// aggregator. A page cannot claim another site's article this way.
+func SameSite(from, to string) bool {
+	f, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	t, err := url.Parse(to)
+	if err != nil || t.Hostname() == "" {
+		return false
+	}
+	if RegistrableDomain(f.Hostname()) == RegistrableDomain(t.Hostname()) {
+		return true
+	}
+	host := strings.ToLower(f.Hostname())
+	for _, h := range crossSiteHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package urlcanon
+
+import "testing"
+
+func TestRegistrableDomain(t *testing.T) {
+	for host, want := range map[string]string{
+		"example.com":          "example.com",
+		"www.news.example.com": "example.com",
+		"EXAMPLE.COM.":         "example.com",
+		"news.bbc.co.uk":       "bbc.co.uk",
+		"bbc.co.uk":            "bbc.co.uk",
+		"localhost":            "localhost",
+		"127.0.0.1":            "127.0.0.1",
+		"2001:db8::1":          "2001:db8::1",
+	} {
+		if got := RegistrableDomain(host); got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestSameSite(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"https://www.example.com/a", "https://example.com/b", true},
+		{"https://m.example.com/a", "https://news.example.com/a", true},
+		{"https://example.com/a", "https://example.org/a", false},
+		{"https://a.co.uk/x", "https://b.co.uk/x", false},
+		{"https://example-com.cdn.ampproject.org/c/s/example.com/a", "https://example.com/a", true},
+		{"https://news.google.com/articles/abc", "https://example.com/a", true},
+		{"https://www.google.com/amp/s/example.com/a", "https://example.com/a", true},
+		{"https://notgoogle.com/a", "https://example.com/a", false},
+		{"https://example.com/a", "/relative", false},
+	}
+	for _, tt := range tests {
+		if got := SameSite(tt.from, tt.to); got != tt.want {
+			t.Errorf("SameSite(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package urlcanon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"discover/internal/model"
+)
+
+// Canonical URLs decide which search results are the same article. Query
+// parameters are kept, since many sites identify articles by them, except
+// for known tracking parameters; the rest are sorted. Host variants (www.,
+// m., amp.) and AMP paths fold into the plain page.
+
+// Version identifies the canonical form. Bump it whenever Canonical changes
+// its output for URLs that are already stored, so stored articles are
+// re-keyed on the next start (store.UpgradeURLCanonicalization).
+const Version = 2
+
+// trackingParams are dropped everywhere unless a domain rule keeps them.
+var trackingParams = []string{
+	"utm_*", "fbclid", "gclid", "gclsrc", "dclid", "gbraid", "wbraid", "msclkid",
+	"twclid", "ttclid", "li_fat_id", "igshid", "yclid", "mc_cid", "mc_eid",
+	"_ga", "_gl", "_hsenc", "_hsmi", "hsctatracking", "mkt_tok", "oly_anon_id",
+	"oly_enc_id", "vero_id", "wickedid", "s_cid", "cmpid", "ncid", "ito",
+	"ref_src", "ref_url", "sr_share", "at_medium", "at_campaign", "spm", "amp",
+	"__twitter_impression",
+}
+
+// hostPrefixes are stripped from the host when something with a dot remains.
+var hostPrefixes = []string{"www.", "m.", "mobile.", "amp."}
+
+type rule struct {
+	domain string
+	keep   []string
+	strip  []string
+}
+
+// Canonicalizer applies the built-in cleanup plus per-domain rules. The zero
+// value has no rules.
+type Canonicalizer struct {
+	rules []rule
+}
+
+// New builds a Canonicalizer from the stored domain rules. Rules are checked
+// most specific domain first.
+func New(rules []model.URLRule) *Canonicalizer {
+	c := &Canonicalizer{rules: make([]rule, 0, len(rules))}
+	for _, r := range rules {
+		domain := NormalizeDomain(r.Domain)
+		if domain == "" {
+			continue
+		}
+		c.rules = append(c.rules, rule{domain: domain, keep: SplitParams(r.KeepParams), strip: SplitParams(r.StripParams)})
+	}
+	sort.SliceStable(c.rules, func(i, j int) bool { return len(c.rules[i].domain) > len(c.rules[j].domain) })
+	return c
+}
+
+// Canonical returns the canonical form of raw and the lowercased host it was
+// served from, which is what source_domain and domain: rules match.
+func (c *Canonicalizer) Canonical(raw string) (normalized, domain string, err error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", "", errors.New("unsupported URL scheme")
+	}
+	domain = strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if domain == "" {
+		return "", "", errors.New("URL has no host")
+	}
+	host := stripHostPrefix(domain)
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Scheme = scheme
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	p := cleanPath(u.EscapedPath())
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		u.Path, u.RawPath = unescaped, p
+	}
+	u.RawQuery = c.cleanQuery(stripHostPrefix(domain), u.RawQuery)
+	return u.String(), domain, nil
+}
+
+// Hash is the key articles are deduplicated by.
+func Hash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func stripHostPrefix(host string) string {
+	for _, prefix := range hostPrefixes {
+		if rest, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(rest, ".") {
+			return rest
+		}
+	}
+	return host
+}
+
+// cleanPath drops AMP variants (a trailing /amp segment or an .amp suffix)
+// and the trailing slash.
+func cleanPath(p string) string {
+	for {
+		trimmed := strings.TrimRight(p, "/")
+		switch {
+		case strings.HasSuffix(strings.ToLower(trimmed), "/amp"):
+			p = trimmed[:len(trimmed)-len("/amp")]
+		case strings.HasSuffix(strings.ToLower(trimmed), ".amp"):
+			p = trimmed[:len(trimmed)-len(".amp")]
+		default:
+			if trimmed == "" {
+				return "/"
+			}
+			return trimmed
+		}
+	}
+}
+
+// cleanQuery drops tracking parameters and sorts the rest. Pairs that do
+// not unescape (or hold a ;) are still filtered by name but kept verbatim,
+// after the others.
+func (c *Canonicalizer) cleanQuery(host, raw string) string {
+	if raw == "" {
+		return ""
+	}
+	r := c.ruleFor(host)
+	values := url.Values{}
+	var verbatim []string
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		rawName, rawValue, _ := strings.Cut(pair, "=")
+		name, nameErr := url.QueryUnescape(rawName)
+		value, valueErr := url.QueryUnescape(rawValue)
+		if nameErr != nil {
+			name = rawName
+		}
+		if !r.keeps(name) {
+			continue
+		}
+		if nameErr != nil || valueErr != nil || strings.Contains(pair, ";") {
+			verbatim = append(verbatim, pair)
+			continue
+		}
+		values.Add(name, value)
+	}
+	sort.Strings(verbatim)
+	if encoded := values.Encode(); encoded != "" {
+		verbatim = append([]string{encoded}, verbatim...)
+	}
+	return strings.Join(verbatim, "&")
+}
+
+func (c *Canonicalizer) ruleFor(host string) rule {
+	for _, r := range c.rules {
+		if host == r.domain || strings.HasSuffix(host, "."+r.domain) {
+			return r
+		}
+	}
+	return rule{}
+}
+
+func (r rule) keeps(name string) bool {
+	if matchAny(r.strip, name) {
+		return false
+	}
+	if len(r.keep) > 0 {
+		return matchAny(r.keep, name)
+	}
+	return !matchAny(trackingParams, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeDomain lowercases a rule domain and drops the host prefixes URLs
+// lose, so a rule for www.example.com also covers example.com.
+func NormalizeDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	return stripHostPrefix(domain)
+}
+
+// SplitParams turns a comma-separated parameter list into lowercased names.
+func SplitParams(list string) []string {
+	var out []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// CheckParams reports a parameter list entry that is not a plain name or a
+// name prefix ending in *.
+func CheckParams(list string) error {
+	for _, p := range SplitParams(list) {
+		name := strings.TrimSuffix(p, "*")
+		if name == "" || strings.ContainsAny(name, "*=&?# ") {
+			return fmt.Errorf("invalid parameter %q", p)
+		}
+	}
+	return nil
+}
+
+// Key returns the canonical form of raw and its hash.
+func (c *Canonicalizer) Key(raw string) (normalized, hash string, err error) {
+	normalized, _, err = c.Canonical(raw)
+	if err != nil {
+		return "", "", err
+	}
+	return normalized, Hash(normalized), nil
+}
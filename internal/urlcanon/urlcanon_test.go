@@ -0,0 +1,106 @@
+package urlcanon
+
+import (
+	"testing"
+
+	"discover/internal/model"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		domain string
+	}{
+		{"tracking params stripped", "https://example.com/a?utm_source=x&utm_medium=y&fbclid=1&gclid=2", "https://example.com/a", "example.com"},
+		{"meaningful query kept", "https://example.com/article?id=1", "https://example.com/article?id=1", "example.com"},
+		{"params sorted", "https://example.com/a?z=1&b=2&a=3&utm_campaign=x", "https://example.com/a?a=3&b=2&z=1", "example.com"},
+		{"repeated params keep order", "https://example.com/a?t=2&t=1", "https://example.com/a?t=2&t=1", "example.com"},
+		{"tracking names case-insensitive", "https://example.com/a?UTM_Source=x&id=1", "https://example.com/a?id=1", "example.com"},
+		{"www folded", "https://www.example.com/a", "https://example.com/a", "www.example.com"},
+		{"m folded", "https://m.example.com/a", "https://example.com/a", "m.example.com"},
+		{"mobile folded", "https://mobile.example.com/a", "https://example.com/a", "mobile.example.com"},
+		{"amp host folded", "https://amp.example.com/a", "https://example.com/a", "amp.example.com"},
+		{"bare prefix host kept", "https://www.com/a", "https://www.com/a", "www.com"},
+		{"amp path segment", "https://example.com/news/story/amp/", "https://example.com/news/story", "example.com"},
+		{"amp suffix", "https://example.com/news/story.amp", "https://example.com/news/story", "example.com"},
+		{"amp query param", "https://example.com/news/story?amp=1", "https://example.com/news/story", "example.com"},
+		{"trailing slash", "https://example.com/news/", "https://example.com/news", "example.com"},
+		{"root path", "https://example.com", "https://example.com/", "example.com"},
+		{"case, port, fragment and user", "HTTPS://user:pw@Example.COM:443/A#top", "https://example.com/A", "example.com"},
+		{"other port kept", "http://example.com:8080/a", "http://example.com:8080/a", "example.com"},
+		{"unparseable pair filtered and kept", "https://example.com/a?utm_source=x&q=a;b&id=%zz&utm_medium=y", "https://example.com/a?id=%zz&q=a;b", "example.com"},
+		{"parsed pairs before verbatim", "https://example.com/a?x=a;b&b=1", "https://example.com/a?b=1&x=a;b", "example.com"},
+	}
+	c := New(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, domain, err := c.Canonical(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || domain != tt.domain {
+				t.Errorf("Canonical(%q) = %q, %q; want %q, %q", tt.raw, got, domain, tt.want, tt.domain)
+			}
+		})
+	}
+}
+
+func TestCanonicalRejects(t *testing.T) {
+	c := New(nil)
+	for _, raw := range []string{"ftp://example.com/a", "mailto:a@example.com", "https:///a", "://bad"} {
+		if got, _, err := c.Canonical(raw); err == nil {
+			t.Errorf("Canonical(%q) = %q, want an error", raw, got)
+		}
+	}
+}
+
+func TestCanonicalDomainRules(t *testing.T) {
+	c := New([]model.URLRule{
+		{Domain: "www.example.com", KeepParams: "id, page"},
+		{Domain: "news.example.com", StripParams: "session*"},
+		{Domain: "shop.example.org", StripParams: "ref", KeepParams: "sku,ref"},
+		{Domain: "tracker.example.net", KeepParams: "utm_source"},
+		{Domain: " "},
+	})
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		// Keep lists drop everything else; the rule covers subdomains.
+		{"https://example.com/a?id=1&page=2&sort=new", "https://example.com/a?id=1&page=2"},
+		{"https://sub.example.com/a?id=1&sort=new", "https://sub.example.com/a?id=1"},
+		// The most specific domain wins.
+		{"https://news.example.com/a?id=1&sessionid=x&sort=new&utm_source=y", "https://news.example.com/a?id=1&sort=new"},
+		// Strip beats keep.
+		{"https://shop.example.org/p?sku=9&ref=home&color=red", "https://shop.example.org/p?sku=9"},
+		// A keep list can bring back a tracking parameter.
+		{"https://tracker.example.net/a?utm_source=feed&utm_medium=x", "https://tracker.example.net/a?utm_source=feed"},
+		{"https://other.example/a?sort=new&utm_source=y", "https://other.example/a?sort=new"},
+	}
+	for _, tt := range tests {
+		got, _, err := c.Canonical(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	c := New(nil)
+	_, a, err := c.Key("https://www.example.com/a/?b=2&a=1&utm_source=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, b, err := c.Key("https://example.com/a?a=1&b=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b || len(a) != 64 {
+		t.Errorf("keys differ: %s vs %s", a, b)
+	}
+}